@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// commandArgs is a parsed command line: positional arguments in order, plus
+// --key=value flags. A bare --key is stored with the value "true".
+type commandArgs struct {
+	positional []string
+	flags      map[string]string
+}
+
+// arg returns the i-th positional argument, or "" when there is none.
+func (a commandArgs) arg(i int) string {
+	if i < len(a.positional) {
+		return a.positional[i]
+	}
+	return ""
+}
+
+func (a commandArgs) flag(name string) (string, bool) {
+	val, ok := a.flags[name]
+	return val, ok
+}
+
+// tokenize splits a line on whitespace, keeping single- or double-quoted
+// text together and honouring backslash escapes outside single quotes.
+func tokenize(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inToken = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// parseArgs separates flags from positional arguments. -h is short for
+// --help, and everything after a lone "--" is positional.
+func parseArgs(tokens []string) commandArgs {
+	args := commandArgs{flags: map[string]string{}}
+	for i, token := range tokens {
+		switch {
+		case token == "--":
+			args.positional = append(args.positional, tokens[i+1:]...)
+			return args
+		case token == "-h":
+			args.flags["help"] = "true"
+		case strings.HasPrefix(token, "--") && len(token) > 2:
+			key, val, found := strings.Cut(token[2:], "=")
+			if !found {
+				val = "true"
+			}
+			args.flags[strings.ToLower(key)] = val
+		default:
+			args.positional = append(args.positional, token)
+		}
+	}
+	return args
+}
+
+// validate checks args against the arity and flags the command declares.
+func (cmd cliCommand) validate(args commandArgs) error {
+	for name := range args.flags {
+		if name == "help" || name == "output" {
+			continue
+		}
+		if _, ok := cmd.flags[name]; !ok {
+			return fmt.Errorf("%s: unknown flag --%s\nusage: %s", cmd.name, name, cmd.usage)
+		}
+	}
+	n := len(args.positional)
+	if n < cmd.minArgs || (cmd.maxArgs >= 0 && n > cmd.maxArgs) {
+		return fmt.Errorf("%s: wrong number of arguments\nusage: %s", cmd.name, cmd.usage)
+	}
+	return nil
+}
+
+func (cmd cliCommand) help() helpEntry {
+	return helpEntry{
+		Name:        cmd.name,
+		Usage:       cmd.usage,
+		Description: cmd.description,
+		Flags:       cmd.flags,
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// splitStatements splits a line on the semicolons that are not quoted or
+// escaped, leaving the quoting in place for tokenize.
+func splitStatements(line string) []string {
+	var statements []string
+	start := 0
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ';':
+			statements = append(statements, line[start:i])
+			start = i + 1
+		}
+	}
+	return append(statements, line[start:])
+}
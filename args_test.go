@@ -0,0 +1,67 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "  explore   canalave-city-area ", expected: []string{"explore", "canalave-city-area"}},
+		{input: `save "my pokedex.json"`, expected: []string{"save", "my pokedex.json"}},
+		{input: `save 'a "b"' c\ d`, expected: []string{"save", `a "b"`, "c d"}},
+		{input: `catch ""`, expected: []string{"catch", ""}},
+	}
+	for _, c := range cases {
+		actual, err := tokenize(c.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.input, err)
+			continue
+		}
+		if !slices.Equal(actual, c.expected) {
+			t.Errorf("%q: expected %q, got %q", c.input, c.expected, actual)
+		}
+	}
+	if _, err := tokenize(`save "unterminated`); err == nil {
+		t.Errorf("expected an error for an unterminated quote")
+	}
+}
+
+func TestParseArgsAndValidate(t *testing.T) {
+	cmd := cliCommand{
+		name:    "catch",
+		usage:   "catch [pokemon] [--ball=<ball>]",
+		maxArgs: 1,
+		flags:   map[string]string{"ball": ""},
+	}
+	args := parseArgs([]string{"pikachu", "--ball=ultra"})
+	if args.arg(0) != "pikachu" {
+		t.Errorf("expected pikachu, got %q", args.arg(0))
+	}
+	if ball, _ := args.flag("ball"); ball != "ultra" {
+		t.Errorf("expected ultra, got %q", ball)
+	}
+	if err := cmd.validate(args); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := cmd.validate(parseArgs([]string{"pikachu", "--shiny"})); err == nil {
+		t.Errorf("expected an error for an unknown flag")
+	}
+	if err := cmd.validate(parseArgs([]string{"pikachu", "eevee"})); err == nil {
+		t.Errorf("expected an error for too many arguments")
+	}
+	if _, ok := parseArgs([]string{"-h"}).flag("help"); !ok {
+		t.Errorf("expected -h to set help")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	actual := splitStatements(`explore pastoria-city-area; catch buizel;save "a;b" ; save c\;d`)
+	expected := []string{"explore pastoria-city-area", " catch buizel", `save "a;b" `, ` save c\;d`}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
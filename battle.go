@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/battle"
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+const (
+	maxBattleMoves = 4
+	// maxMoveLookups bounds the /move requests made to fill a move set, since
+	// many learned moves are status moves with no power.
+	maxMoveLookups = 12
+)
+
+// battleMoves picks up to maxBattleMoves damaging moves the pokemon learned by
+// level-up at or below its level, most recently learned first.
+func battleMoves(client *pokeapi.Client, pokemon pokeapi.PokemonAPIResponse, level int) []battle.Move {
+	learnedAt := map[string]int{}
+	for _, m := range pokemon.Moves {
+		for _, detail := range m.VersionGroupDetails {
+			if detail.MoveLearnMethod.Name != "level-up" || detail.LevelLearnedAt > level {
+				continue
+			}
+			if current, ok := learnedAt[m.Move.Name]; !ok || detail.LevelLearnedAt > current {
+				learnedAt[m.Move.Name] = detail.LevelLearnedAt
+			}
+		}
+	}
+	names := make([]string, 0, len(learnedAt))
+	for name := range learnedAt {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if learnedAt[names[i]] != learnedAt[names[j]] {
+			return learnedAt[names[i]] > learnedAt[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	var moves []battle.Move
+	for i, name := range names {
+		if len(moves) == maxBattleMoves || i == maxMoveLookups {
+			break
+		}
+		move, err := client.GetMove(name)
+		if err != nil || move.Power == 0 {
+			continue
+		}
+		moves = append(moves, battle.NewMove(move))
+	}
+	return moves
+}
+
+func commandBattle(config *config, args commandArgs) (any, error) {
+	if config.battle != nil {
+		return nil, fmt.Errorf("You are already battling %s, attack or run first", config.battle.Wild.Name)
+	}
+	name := strings.ToLower(args.arg(0))
+	caught, ok := config.pokedex[name]
+	if !ok {
+		return nil, errors.New("You have not caught that pokemon")
+	}
+	wild, err := findWild(config, strings.ToLower(args.arg(1)))
+	if err != nil {
+		return nil, err
+	}
+	wildPokemon, err := config.client.GetPokemon(wild.name)
+	if err != nil {
+		return nil, friendlyError(err, "pokemon called "+wild.name)
+	}
+	level := caught.Level
+	if level <= 0 {
+		level = defaultWildLevel
+	}
+	player := battle.NewCombatant(caught.Pokemon, level, battleMoves(config.client, caught.Pokemon, level))
+	opponent := battle.NewCombatant(wildPokemon, wild.level, battleMoves(config.client, wildPokemon, wild.level))
+	config.battle = battle.New(player, opponent, nil)
+	config.battle.Effectiveness = func(moveType string, defenderTypes []string) float64 {
+		// an unknown type chart entry only costs the bonus, not the turn
+		m, _ := config.typeChart.Effectiveness(moveType, defenderTypes)
+		return m
+	}
+	return newBattleStatus(config.battle, nil), nil
+}
+
+func commandAttack(config *config, args commandArgs) (any, error) {
+	b := config.battle
+	if b == nil {
+		return nil, errors.New("You are not in a battle, start one with battle <pokemon>")
+	}
+	move := b.Player.Moves[0]
+	if name := strings.ToLower(args.arg(0)); name != "" {
+		var ok bool
+		if move, ok = b.Player.Move(name); !ok {
+			return nil, fmt.Errorf("%s does not know %s, its moves are %s", b.Player.Name, name, strings.Join(moveNames(b.Player), ", "))
+		}
+	}
+	events := b.Turn(move)
+	if b.Over() {
+		config.battle = nil
+	}
+	return newBattleStatus(b, events), nil
+}
+
+func commandRun(config *config, args commandArgs) (any, error) {
+	if config.battle == nil {
+		return nil, errors.New("You are not in a battle")
+	}
+	config.battle = nil
+	return messageResult{Message: "Got away safely!"}, nil
+}
+
+func moveNames(c *battle.Combatant) []string {
+	names := make([]string, 0, len(c.Moves))
+	for _, move := range c.Moves {
+		names = append(names, move.Name)
+	}
+	return names
+}
+
+type combatantStatus struct {
+	Name  string   `json:"name"`
+	Level int      `json:"level"`
+	HP    int      `json:"hp"`
+	MaxHP int      `json:"max_hp"`
+	Moves []string `json:"moves"`
+}
+
+func newCombatantStatus(c *battle.Combatant) combatantStatus {
+	return combatantStatus{Name: c.Name, Level: c.Level, HP: c.HP, MaxHP: c.MaxHP, Moves: moveNames(c)}
+}
+
+type battleStatus struct {
+	Player combatantStatus `json:"player"`
+	Wild   combatantStatus `json:"wild"`
+	Events []battle.Event  `json:"events"`
+	Over   bool            `json:"over"`
+}
+
+func newBattleStatus(b *battle.Battle, events []battle.Event) battleStatus {
+	if events == nil {
+		events = []battle.Event{}
+	}
+	return battleStatus{
+		Player: newCombatantStatus(b.Player),
+		Wild:   newCombatantStatus(b.Wild),
+		Events: events,
+		Over:   b.Over(),
+	}
+}
+
+func (r battleStatus) WriteText(w io.Writer) error {
+	if len(r.Events) == 0 {
+		fmt.Fprintf(w, "A wild %s (level %d) appeared!\n", r.Wild.Name, r.Wild.Level)
+		fmt.Fprintf(w, "Go! %s (level %d)!\n", r.Player.Name, r.Player.Level)
+		fmt.Fprintln(w, "Moves:", strings.Join(r.Player.Moves, ", "))
+	}
+	for _, event := range r.Events {
+		fmt.Fprintf(w, "%s used %s!\n", event.Attacker, event.Move)
+		if event.Missed {
+			fmt.Fprintln(w, "It missed!")
+			continue
+		}
+		switch {
+		case event.Effectiveness == 0:
+			fmt.Fprintf(w, "It doesn't affect %s...\n", event.Defender)
+		case event.Effectiveness > 1:
+			fmt.Fprintln(w, "It's super effective!")
+		case event.Effectiveness < 1:
+			fmt.Fprintln(w, "It's not very effective...")
+		}
+		fmt.Fprintf(w, "%s took %d damage.\n", event.Defender, event.Damage)
+		if event.Fainted {
+			fmt.Fprintf(w, "%s fainted!\n", event.Defender)
+		}
+	}
+	fmt.Fprintf(w, "%s HP: %d/%d\n", r.Player.Name, r.Player.HP, r.Player.MaxHP)
+	fmt.Fprintf(w, "%s HP: %d/%d\n", r.Wild.Name, r.Wild.HP, r.Wild.MaxHP)
+	return nil
+}
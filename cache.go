@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+type cacheStatsResult struct {
+	pokecache.Stats
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+func (r cacheStatsResult) WriteText(w io.Writer) error {
+	fmt.Fprintln(w, "Entries:", r.Entries)
+	fmt.Fprintln(w, "Stale entries:", r.Stale)
+	fmt.Fprintln(w, "Bytes:", r.Bytes)
+	fmt.Fprintln(w, "Hits:", r.Hits)
+	fmt.Fprintln(w, "Disk hits:", r.DiskHits)
+	fmt.Fprintln(w, "Misses:", r.Misses)
+	fmt.Fprintln(w, "Shared fetches:", r.Shared)
+	fmt.Fprintln(w, "Revalidations:", r.Revalidations)
+	fmt.Fprintln(w, "Evictions:", r.Evictions)
+	fmt.Fprintln(w, "Expirations:", r.Expirations)
+	fmt.Fprintf(w, "Hit ratio: %.1f%%\n", r.HitRatio*100)
+	return nil
+}
+
+type cacheEntryInfo struct {
+	Key        string  `json:"key"`
+	Size       int     `json:"size"`
+	AgeSeconds float64 `json:"age_seconds"`
+}
+
+type cacheListResult struct {
+	Entries []cacheEntryInfo `json:"entries"`
+}
+
+func (r cacheListResult) WriteText(w io.Writer) error {
+	for _, entry := range r.Entries {
+		age := time.Duration(entry.AgeSeconds * float64(time.Second)).Round(time.Second)
+		fmt.Fprintf(w, "%s (%d bytes, %s old)\n", entry.Key, entry.Size, age)
+	}
+	return nil
+}
+
+func (r cacheListResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Entries))
+	for _, entry := range r.Entries {
+		age := time.Duration(entry.AgeSeconds * float64(time.Second)).Round(time.Second)
+		rows = append(rows, []string{entry.Key, strconv.Itoa(entry.Size), age.String()})
+	}
+	return []string{"key", "bytes", "age"}, rows
+}
+
+func commandCache(config *config, args commandArgs) (any, error) {
+	switch sub := strings.ToLower(args.arg(0)); sub {
+	case "stats":
+		stats := config.cache.Stats()
+		return cacheStatsResult{Stats: stats, HitRatio: stats.HitRatio()}, nil
+	case "list":
+		res := cacheListResult{Entries: []cacheEntryInfo{}}
+		for _, entry := range config.cache.Entries() {
+			res.Entries = append(res.Entries, cacheEntryInfo{
+				Key:        entry.Key,
+				Size:       entry.Size,
+				AgeSeconds: entry.Age.Seconds(),
+			})
+		}
+		return res, nil
+	case "clear":
+		if err := config.cache.Clear(); err != nil {
+			return nil, err
+		}
+		return messageResult{Message: "Cache cleared"}, nil
+	case "purge":
+		prefix := args.arg(1)
+		if prefix == "" {
+			return nil, fmt.Errorf("cache purge needs a URL prefix, use cache clear to remove everything")
+		}
+		removed, err := config.cache.Purge(prefix)
+		if err != nil {
+			return nil, err
+		}
+		return messageResult{Message: fmt.Sprintf("Purged %d cached responses starting with %s", removed, prefix)}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache subcommand %q\nusage: %s", sub, cmdRegistry["cache"].usage)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// completeLine is the lineedit.Completer of the REPL. The first word of a
+// statement completes to a command name; arguments complete to what the
+// command takes, as far as the session knows it.
+func completeLine(config *config, head string) (int, []string) {
+	statement := strings.LastIndex(head, ";") + 1
+	start := strings.LastIndexAny(head, " \t;") + 1
+	args := strings.Fields(head[statement:start])
+	var names []string
+	if len(args) == 0 {
+		names = sortedKeys(cmdRegistry)
+	} else {
+		names = argumentNames(config, strings.ToLower(args[0]), len(args)-1)
+	}
+	word := head[start:]
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, word) {
+			matches = append(matches, name)
+		}
+	}
+	return start, matches
+}
+
+// argumentNames lists the values the argument at index i of cmd can take.
+func argumentNames(config *config, cmd string, i int) []string {
+	switch {
+	case cmd == "help" && i == 0:
+		return sortedKeys(cmdRegistry)
+	case cmd == "explore" && i == 0:
+		return knownAreas(config)
+	case cmd == "game" && i == 0:
+		// only versions already listed, completion shouldn't block on a fetch
+		return append([]string{"all"}, config.nameIndex["version"]...)
+	case (cmd == "catch" && i == 0) || (cmd == "battle" && i == 1):
+		return encounteredPokemon(config)
+	case cmd == "inspect" || cmd == "evolve" || cmd == "battle" || cmd == "weakness" || cmd == "evolution":
+		if i == 0 {
+			return sortedKeys(config.pokedex)
+		}
+	}
+	return nil
+}
+
+// knownAreas lists the location areas seen on map pages and those whose
+// responses are in the cache.
+func knownAreas(config *config) []string {
+	areas := map[string]bool{}
+	for area := range config.seenAreas {
+		areas[area] = true
+	}
+	if config.cache != nil {
+		prefix := config.client.LocationAreaURL("")
+		for _, entry := range config.cache.Entries() {
+			area, ok := strings.CutPrefix(entry.Key, prefix)
+			if ok && area != "" && !strings.ContainsAny(area, "?/") {
+				areas[area] = true
+			}
+		}
+	}
+	return sortedKeys(areas)
+}
+
+// encounteredPokemon lists the pokemon of the last explored area, in the
+// selected game if any.
+func encounteredPokemon(config *config) []string {
+	names := make([]string, 0, len(config.location.PokemonEncounters))
+	for _, occurrence := range config.location.PokemonEncounters {
+		if config.game != "" && len(versionEncounters(occurrence.VersionDetails, config.game)) == 0 {
+			continue
+		}
+		names = append(names, occurrence.Pokemon.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+func TestCompleteLine(t *testing.T) {
+	saved := cmdRegistry
+	t.Cleanup(func() { cmdRegistry = saved })
+	cmdRegistry = map[string]cliCommand{"catch": {}, "cache": {}, "explore": {}, "inspect": {}}
+
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	client := pokeapi.NewClient("https://pokeapi.test/api/v2", nil, cache)
+	cache.Add(client.LocationAreaURL("eterna-city-area"), []byte("{}"))
+	cache.Add(client.LocationAreaURL("")+"?offset=20&limit=20", []byte("{}"))
+	cfg := &config{
+		client:    client,
+		cache:     cache,
+		seenAreas: map[string]bool{"canalave-city-area": true},
+		pokedex:   map[string]caughtPokemon{"pikachu": {}},
+	}
+	err := json.Unmarshal([]byte(`{"pokemon_encounters":[{"pokemon":{"name":"tentacool"}},{"pokemon":{"name":"tentacruel"}}]}`), &cfg.location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		head  string
+		start int
+		want  []string
+	}{
+		{"ca", 0, []string{"cache", "catch"}},
+		{"map; ex", 5, []string{"explore"}},
+		{"explore ", 8, []string{"canalave-city-area", "eterna-city-area"}},
+		{"catch tentacr", 6, []string{"tentacruel"}},
+		{"inspect p", 8, []string{"pikachu"}},
+		{"inspect pikachu ", 16, nil},
+	}
+	for _, c := range cases {
+		start, got := completeLine(cfg, c.head)
+		if start != c.start || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("completeLine(%q) = %d, %v, want %d, %v", c.head, start, got, c.start, c.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+// defaultWildLevel is used when the pokemon has no encounter data, which only
+// happens in sandbox mode.
+const defaultWildLevel = 5
+
+type wildEncounter struct {
+	name  string
+	level int
+}
+
+// pickEncounter rolls a wild pokemon from the area, weighting every encounter
+// detail by its chance. When pokemon or version is not empty only matching
+// encounters are considered. It reports false if nothing matches.
+func pickEncounter(area pokeapi.LocationAPIResponse, pokemon, version string) (wildEncounter, bool) {
+	type candidate struct {
+		name               string
+		chance             int
+		minLevel, maxLevel int
+	}
+	var candidates []candidate
+	total := 0
+	for _, encounter := range area.PokemonEncounters {
+		if pokemon != "" && encounter.Pokemon.Name != pokemon {
+			continue
+		}
+		for _, details := range encounter.VersionDetails {
+			if version != "" && details.Version.Name != version {
+				continue
+			}
+			for _, detail := range details.EncounterDetails {
+				candidates = append(candidates, candidate{
+					name:     encounter.Pokemon.Name,
+					chance:   detail.Chance,
+					minLevel: detail.MinLevel,
+					maxLevel: detail.MaxLevel,
+				})
+				total += detail.Chance
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return wildEncounter{}, false
+	}
+	picked := candidates[rand.Intn(len(candidates))]
+	if total > 0 {
+		roll := rand.Intn(total)
+		for _, c := range candidates {
+			if roll < c.chance {
+				picked = c
+				break
+			}
+			roll -= c.chance
+		}
+	}
+	level := picked.minLevel
+	if picked.maxLevel > picked.minLevel {
+		level += rand.Intn(picked.maxLevel - picked.minLevel + 1)
+	}
+	if level <= 0 {
+		level = defaultWildLevel
+	}
+	return wildEncounter{name: picked.name, level: level}, true
+}
+
+// encounterPlace names the explored area, and the selected game if any, for
+// messages.
+func encounterPlace(config *config) string {
+	if config.game != "" {
+		return config.area + " in " + config.game
+	}
+	return config.area
+}
+
+// areaPokemon lists the pokemon met in the explored area in the selected
+// game, the only ones that can be caught outside sandbox mode.
+func areaPokemon(config *config) []string {
+	var names []string
+	for _, encounter := range config.location.PokemonEncounters {
+		for _, details := range encounter.VersionDetails {
+			if config.game == "" || details.Version.Name == config.game {
+				names = append(names, encounter.Pokemon.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// findWild picks the wild pokemon to face in the explored area and selected
+// game, honouring sandbox mode where any named pokemon can show up.
+func findWild(config *config, pokemon string) (wildEncounter, error) {
+	wild, found := pickEncounter(config.location, pokemon, config.game)
+	if found {
+		return wild, nil
+	}
+	if !config.sandbox {
+		if config.area == "" {
+			return wild, errors.New("Please explore an area before trying to catch a pokemon")
+		}
+		where := encounterPlace(config)
+		if pokemon == "" {
+			return wild, fmt.Errorf("There are no wild pokemon in %s", where)
+		}
+		return wild, fmt.Errorf("There is no %s in %s", pokemon, where)
+	}
+	if pokemon == "" {
+		return wild, errors.New("Please name the pokemon to catch")
+	}
+	return wildEncounter{name: pokemon, level: defaultWildLevel}, nil
+}
+
+// versionEncounter sums up how a pokemon is met in one game version.
+type versionEncounter struct {
+	Version  string   `json:"version"`
+	Chance   int      `json:"chance"`
+	MinLevel int      `json:"min_level"`
+	MaxLevel int      `json:"max_level"`
+	Methods  []string `json:"methods"`
+}
+
+// versionEncounters sums up the encounter details of each version, or of
+// version alone when it is not empty. Chances of the details add up.
+func versionEncounters(details []pokeapi.VersionEncounterDetail, version string) []versionEncounter {
+	var encounters []versionEncounter
+	for _, d := range details {
+		if version != "" && d.Version.Name != version {
+			continue
+		}
+		e := versionEncounter{Version: d.Version.Name, Methods: []string{}}
+		for _, detail := range d.EncounterDetails {
+			e.Chance += detail.Chance
+			if e.MinLevel == 0 || detail.MinLevel < e.MinLevel {
+				e.MinLevel = detail.MinLevel
+			}
+			e.MaxLevel = max(e.MaxLevel, detail.MaxLevel)
+			if !slices.Contains(e.Methods, detail.Method.Name) {
+				e.Methods = append(e.Methods, detail.Method.Name)
+			}
+		}
+		encounters = append(encounters, e)
+	}
+	return encounters
+}
+
+// levels is the level range, e.g. "20-30", or a single level.
+func (e versionEncounter) levels() string {
+	if e.MaxLevel > e.MinLevel {
+		return fmt.Sprint(e.MinLevel, "-", e.MaxLevel)
+	}
+	return fmt.Sprint(e.MinLevel)
+}
+
+func (e versionEncounter) String() string {
+	return fmt.Sprintf("%s %d%% lv %s (%s)", e.Version, e.Chance, e.levels(), strings.Join(e.Methods, ", "))
+}
+
+type pokeball struct {
+	name          string
+	multiplier    float64
+	alwaysCatches bool
+}
+
+var pokeballs = map[string]pokeball{
+	"poke":   {name: "Pokeball", multiplier: 1},
+	"great":  {name: "Great Ball", multiplier: 1.5},
+	"ultra":  {name: "Ultra Ball", multiplier: 2},
+	"master": {name: "Master Ball", multiplier: 1, alwaysCatches: true},
+}
+
+func parseBall(name string) (pokeball, error) {
+	if name == "" {
+		name = "poke"
+	}
+	ball, ok := pokeballs[strings.ToLower(name)]
+	if !ok {
+		return pokeball{}, fmt.Errorf("unknown ball %q, expected one of poke, great, ultra, master", name)
+	}
+	return ball, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+const testArea = `{"name":"test-area","pokemon_encounters":[
+	{"pokemon":{"name":"tentacool"},"version_details":[{"encounter_details":[{"chance":60,"min_level":20,"max_level":30}]}]},
+	{"pokemon":{"name":"shellos"},"version_details":[{"encounter_details":[{"chance":40,"min_level":3,"max_level":3}]}]}
+]}`
+
+func TestPickEncounter(t *testing.T) {
+	var area pokeapi.LocationAPIResponse
+	if err := json.Unmarshal([]byte(testArea), &area); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		wild, ok := pickEncounter(area, "tentacool", "")
+		if !ok || wild.name != "tentacool" {
+			t.Fatalf("expected tentacool, got %+v", wild)
+		}
+		if wild.level < 20 || wild.level > 30 {
+			t.Errorf("level %d outside of encounter range", wild.level)
+		}
+	}
+	if _, ok := pickEncounter(area, "mewtwo", ""); ok {
+		t.Errorf("expected mewtwo not to be encountered")
+	}
+	if wild, ok := pickEncounter(area, "", ""); !ok || (wild.name != "tentacool" && wild.name != "shellos") {
+		t.Errorf("expected a pokemon from the area, got %+v", wild)
+	}
+}
+
+const testVersionedArea = `{"name":"test-area","pokemon_encounters":[
+	{"pokemon":{"name":"tentacool"},"version_details":[
+		{"version":{"name":"diamond"},"encounter_details":[
+			{"chance":60,"min_level":20,"max_level":30,"method":{"name":"surf"}},
+			{"chance":30,"min_level":15,"max_level":25,"method":{"name":"surf"}}]},
+		{"version":{"name":"pearl"},"encounter_details":[{"chance":5,"min_level":40,"max_level":40,"method":{"name":"super-rod"}}]}]},
+	{"pokemon":{"name":"shellos"},"version_details":[
+		{"version":{"name":"pearl"},"encounter_details":[{"chance":40,"min_level":3,"max_level":3,"method":{"name":"walk"}}]}]}
+]}`
+
+func TestPickEncounterInVersion(t *testing.T) {
+	var area pokeapi.LocationAPIResponse
+	if err := json.Unmarshal([]byte(testVersionedArea), &area); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		wild, ok := pickEncounter(area, "", "diamond")
+		if !ok || wild.name != "tentacool" || wild.level < 15 || wild.level > 30 {
+			t.Fatalf("expected a diamond tentacool, got %+v", wild)
+		}
+	}
+	if wild, ok := pickEncounter(area, "tentacool", "pearl"); !ok || wild.level != 40 {
+		t.Errorf("expected the pearl tentacool at level 40, got %+v", wild)
+	}
+	if _, ok := pickEncounter(area, "shellos", "diamond"); ok {
+		t.Errorf("expected shellos not to be encountered in diamond")
+	}
+}
+
+func TestVersionEncounters(t *testing.T) {
+	var area pokeapi.LocationAPIResponse
+	if err := json.Unmarshal([]byte(testVersionedArea), &area); err != nil {
+		t.Fatal(err)
+	}
+	details := area.PokemonEncounters[0].VersionDetails
+	all := versionEncounters(details, "")
+	if len(all) != 2 {
+		t.Fatalf("expected both versions, got %+v", all)
+	}
+	if got, want := all[0].String(), "diamond 90% lv 15-30 (surf)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := all[1].String(), "pearl 5% lv 40 (super-rod)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if pearl := versionEncounters(details, "pearl"); len(pearl) != 1 || pearl[0].Version != "pearl" {
+		t.Errorf("expected only pearl, got %+v", pearl)
+	}
+	if none := versionEncounters(details, "platinum"); len(none) != 0 {
+		t.Errorf("expected no platinum encounters, got %+v", none)
+	}
+}
+
+func TestExploreResolvesVersionFlag(t *testing.T) {
+	config := &config{
+		client:    pokeapi.NewClientWithSource("/api/v2", mapSource{"/api/v2/location-area/test-area": testVersionedArea}, nil),
+		nameIndex: map[string][]string{"location-area": {"test-area"}, "version": {"diamond", "pearl"}},
+	}
+	res, err := commandExplore(config, parseArgs([]string{"test-area", "--version=diamnod"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if explored := res.(exploreResult); explored.Version != "diamond" || len(explored.Pokemon) != 1 || explored.Pokemon[0] != "tentacool" {
+		t.Errorf("expected the diamond encounters, got %+v", explored)
+	}
+	if _, err := commandExplore(config, parseArgs([]string{"test-area", "--version=red"})); err == nil {
+		t.Errorf("expected an unknown version to be refused")
+	}
+}
+
+func TestCatchResolvesAgainstArea(t *testing.T) {
+	config := &config{
+		client:  pokeapi.NewClientWithSource("/api/v2", mapSource{"/api/v2/pokemon/tentacool": `{"name":"tentacool","base_experience":67}`}, nil),
+		area:    "test-area",
+		game:    "diamond",
+		pokedex: map[string]caughtPokemon{},
+	}
+	if err := json.Unmarshal([]byte(testVersionedArea), &config.location); err != nil {
+		t.Fatal(err)
+	}
+	res, err := commandCatch(config, parseArgs([]string{"tentacol", "--ball=master"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caught := res.(catchResult); caught.Pokemon != "tentacool" || !caught.Caught {
+		t.Errorf("expected tentacool to be caught, got %+v", caught)
+	}
+	// shellos lives here, but only in pearl
+	_, err = commandCatch(config, parseArgs([]string{"shellos"}))
+	if err == nil || err.Error() != "There is no shellos in test-area in diamond" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
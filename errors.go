@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+// friendlyError rewords PokeAPI failures for the user. what names the
+// resource that was asked for, e.g. "pokemon pikachuu".
+func friendlyError(err error, what string) error {
+	var httpErr *pokeapi.HTTPError
+	var mirrorErr *pokeapi.MirrorError
+	switch {
+	case errors.As(err, &mirrorErr):
+		// already says what is missing, and where
+		return err
+	case errors.Is(err, pokeapi.ErrNotFound):
+		return fmt.Errorf("There is no %s", what)
+	case errors.Is(err, pokeapi.ErrRateLimited):
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+			return fmt.Errorf("PokeAPI is rate limiting us, try again in %s", httpErr.RetryAfter)
+		}
+		return errors.New("PokeAPI is rate limiting us, try again in a moment")
+	case errors.Is(err, pokeapi.ErrUpstream):
+		return fmt.Errorf("PokeAPI failed to return %s, try again later (%w)", what, err)
+	}
+	return err
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+// speciesOf returns the species name of a pokemon, preferring the pokedex
+// copy so caught pokemon need no request.
+func speciesOf(config *config, name string) (string, error) {
+	pokemon := config.pokedex[name].Pokemon
+	if pokemon.Name == "" {
+		var err error
+		if pokemon, err = config.client.GetPokemon(name); err != nil {
+			return "", friendlyError(err, "pokemon called "+name)
+		}
+	}
+	if pokemon.Species.Name == "" {
+		return name, nil
+	}
+	return pokemon.Species.Name, nil
+}
+
+func fetchEvolutionChain(config *config, species string) (pokeapi.ChainLink, error) {
+	speciesData, err := config.client.GetPokemonSpecies(species)
+	if err != nil {
+		return pokeapi.ChainLink{}, friendlyError(err, "species called "+species)
+	}
+	if speciesData.EvolutionChain.URL == "" {
+		return pokeapi.ChainLink{}, fmt.Errorf("%s has no evolution chain", species)
+	}
+	chain, err := config.client.GetEvolutionChain(speciesData.EvolutionChain.URL)
+	if err != nil {
+		return pokeapi.ChainLink{}, err
+	}
+	return chain.Chain, nil
+}
+
+// findLink returns the link of species within the chain.
+func findLink(link pokeapi.ChainLink, species string) (pokeapi.ChainLink, bool) {
+	if link.Species.Name == species {
+		return link, true
+	}
+	for _, next := range link.EvolvesTo {
+		if found, ok := findLink(next, species); ok {
+			return found, true
+		}
+	}
+	return pokeapi.ChainLink{}, false
+}
+
+// describeTrigger turns an evolution detail into a short phrase such as
+// "level 16", "use thunder-stone" or "trade holding metal-coat".
+func describeTrigger(detail pokeapi.EvolutionDetail) string {
+	var parts []string
+	switch detail.Trigger.Name {
+	case "level-up":
+		if detail.MinLevel > 0 {
+			parts = append(parts, "level "+strconv.Itoa(detail.MinLevel))
+		} else {
+			parts = append(parts, "level up")
+		}
+	case "use-item":
+		parts = append(parts, "use "+detail.Item.Name)
+	case "trade":
+		parts = append(parts, "trade")
+		if detail.TradeSpecies.Name != "" {
+			parts = append(parts, "for "+detail.TradeSpecies.Name)
+		}
+	case "":
+	default:
+		parts = append(parts, detail.Trigger.Name)
+	}
+	if detail.HeldItem.Name != "" {
+		parts = append(parts, "holding "+detail.HeldItem.Name)
+	}
+	if detail.MinHappiness > 0 {
+		parts = append(parts, "with friendship "+strconv.Itoa(detail.MinHappiness))
+	}
+	if detail.MinAffection > 0 {
+		parts = append(parts, "with affection "+strconv.Itoa(detail.MinAffection))
+	}
+	if detail.MinBeauty > 0 {
+		parts = append(parts, "with beauty "+strconv.Itoa(detail.MinBeauty))
+	}
+	if detail.KnownMove.Name != "" {
+		parts = append(parts, "knowing "+detail.KnownMove.Name)
+	}
+	if detail.KnownMoveType.Name != "" {
+		parts = append(parts, "knowing a "+detail.KnownMoveType.Name+" move")
+	}
+	if detail.Location.Name != "" {
+		parts = append(parts, "at "+detail.Location.Name)
+	}
+	if detail.TimeOfDay != "" {
+		parts = append(parts, "during the "+detail.TimeOfDay)
+	}
+	if detail.PartySpecies.Name != "" {
+		parts = append(parts, "with "+detail.PartySpecies.Name+" in the party")
+	}
+	if detail.PartyType.Name != "" {
+		parts = append(parts, "with a "+detail.PartyType.Name+" pokemon in the party")
+	}
+	if detail.Gender != nil {
+		switch *detail.Gender {
+		case 1:
+			parts = append(parts, "if female")
+		case 2:
+			parts = append(parts, "if male")
+		}
+	}
+	if detail.RelativePhysicalStats != nil {
+		switch {
+		case *detail.RelativePhysicalStats > 0:
+			parts = append(parts, "with attack above defense")
+		case *detail.RelativePhysicalStats < 0:
+			parts = append(parts, "with attack below defense")
+		default:
+			parts = append(parts, "with attack equal to defense")
+		}
+	}
+	if detail.NeedsOverworldRain {
+		parts = append(parts, "while raining")
+	}
+	if detail.TurnUpsideDown {
+		parts = append(parts, "holding the console upside down")
+	}
+	return strings.Join(parts, " ")
+}
+
+type evolutionNode struct {
+	Species   string          `json:"species"`
+	Triggers  []string        `json:"triggers,omitempty"`
+	EvolvesTo []evolutionNode `json:"evolves_to"`
+}
+
+func newEvolutionNode(link pokeapi.ChainLink) evolutionNode {
+	node := evolutionNode{Species: link.Species.Name, EvolvesTo: []evolutionNode{}}
+	for _, detail := range link.EvolutionDetails {
+		node.Triggers = append(node.Triggers, describeTrigger(detail))
+	}
+	for _, next := range link.EvolvesTo {
+		node.EvolvesTo = append(node.EvolvesTo, newEvolutionNode(next))
+	}
+	return node
+}
+
+type evolutionResult struct {
+	Chain evolutionNode `json:"chain"`
+}
+
+func (r evolutionResult) WriteText(w io.Writer) error {
+	fmt.Fprintln(w, r.Chain.Species)
+	writeEvolutionTree(w, r.Chain.EvolvesTo, "")
+	return nil
+}
+
+func writeEvolutionTree(w io.Writer, nodes []evolutionNode, prefix string) {
+	for i, node := range nodes {
+		branch, indent := "├─ ", "│  "
+		if i == len(nodes)-1 {
+			branch, indent = "└─ ", "   "
+		}
+		line := node.Species
+		if len(node.Triggers) > 0 {
+			line += " (" + strings.Join(node.Triggers, " or ") + ")"
+		}
+		fmt.Fprintln(w, prefix+branch+line)
+		writeEvolutionTree(w, node.EvolvesTo, prefix+indent)
+	}
+}
+
+func commandEvolution(config *config, args commandArgs) (any, error) {
+	species, err := speciesOf(config, strings.ToLower(args.arg(0)))
+	if err != nil {
+		return nil, err
+	}
+	chain, err := fetchEvolutionChain(config, species)
+	if err != nil {
+		return nil, err
+	}
+	return evolutionResult{Chain: newEvolutionNode(chain)}, nil
+}
+
+// levelOnly reports whether the detail is a plain level-up with no other
+// condition. Nil pointer fields in plain stand for conditions that are
+// absent, so a 0 stat comparison or gender still rules the detail out.
+func levelOnly(detail pokeapi.EvolutionDetail) bool {
+	plain := pokeapi.EvolutionDetail{Trigger: detail.Trigger, MinLevel: detail.MinLevel}
+	return detail.Trigger.Name == "level-up" && detail.MinLevel > 0 && detail == plain
+}
+
+// levelEvolution returns the species the caught pokemon evolves into by
+// level-up at its current level. Other triggers can't be checked from the
+// pokedex, so they never qualify.
+func levelEvolution(link pokeapi.ChainLink, level int) (string, error) {
+	var needed []string
+	for _, next := range link.EvolvesTo {
+		for _, detail := range next.EvolutionDetails {
+			if levelOnly(detail) && detail.MinLevel <= level {
+				return next.Species.Name, nil
+			}
+			needed = append(needed, next.Species.Name+" needs "+describeTrigger(detail))
+		}
+	}
+	if len(needed) == 0 {
+		return "", fmt.Errorf("%s does not evolve any further", link.Species.Name)
+	}
+	return "", fmt.Errorf("%s cannot evolve at level %d: %s", link.Species.Name, level, strings.Join(needed, ", "))
+}
+
+// defaultPokemon fetches the default variety of a species, whose name can
+// differ from the species, e.g. aegislash-shield for aegislash.
+func defaultPokemon(config *config, species string) (pokeapi.PokemonAPIResponse, error) {
+	speciesData, err := config.client.GetPokemonSpecies(species)
+	if err != nil {
+		return pokeapi.PokemonAPIResponse{}, friendlyError(err, "species called "+species)
+	}
+	name := species
+	for _, variety := range speciesData.Varieties {
+		if variety.IsDefault {
+			name = variety.Pokemon.Name
+			break
+		}
+	}
+	pokemon, err := config.client.GetPokemon(name)
+	if err != nil {
+		return pokemon, friendlyError(err, "pokemon called "+name)
+	}
+	return pokemon, nil
+}
+
+func commandEvolve(config *config, args commandArgs) (any, error) {
+	name := strings.ToLower(args.arg(0))
+	caught, ok := config.pokedex[name]
+	if !ok {
+		return nil, errors.New("You have not caught that pokemon")
+	}
+	species, err := speciesOf(config, name)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := fetchEvolutionChain(config, species)
+	if err != nil {
+		return nil, err
+	}
+	link, ok := findLink(chain, species)
+	if !ok {
+		return nil, fmt.Errorf("%s is missing from its own evolution chain", species)
+	}
+	evolvedSpecies, err := levelEvolution(link, caught.Level)
+	if err != nil {
+		return nil, err
+	}
+	evolved, err := defaultPokemon(config, evolvedSpecies)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := config.pokedex[evolved.Name]; exists {
+		return nil, fmt.Errorf("You already have a %s, evolving %s would replace it", evolved.Name, name)
+	}
+	delete(config.pokedex, name)
+	config.pokedex[evolved.Name] = caughtPokemon{
+		Pokemon:  evolved,
+		Level:    caught.Level,
+		CaughtAt: caught.CaughtAt,
+		Area:     caught.Area,
+	}
+	autosave(config)
+	return messageResult{Message: fmt.Sprintf("%s evolved into %s!", name, evolved.Name)}, nil
+}
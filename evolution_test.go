@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+const testChain = `{"species":{"name":"pichu"},"evolves_to":[
+	{"species":{"name":"pikachu"},"evolution_details":[{"trigger":{"name":"level-up"},"min_happiness":220}],"evolves_to":[
+		{"species":{"name":"raichu"},"evolution_details":[{"trigger":{"name":"use-item"},"item":{"name":"thunder-stone"}}],"evolves_to":[]}
+	]},
+	{"species":{"name":"fakechu"},"evolution_details":[{"trigger":{"name":"level-up"},"min_level":30}],"evolves_to":[]}
+]}`
+
+func TestEvolutionChain(t *testing.T) {
+	var chain pokeapi.ChainLink
+	if err := json.Unmarshal([]byte(testChain), &chain); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	evolutionResult{Chain: newEvolutionNode(chain)}.WriteText(&b)
+	expected := `pichu
+├─ pikachu (level up with friendship 220)
+│  └─ raichu (use thunder-stone)
+└─ fakechu (level 30)
+`
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+
+	if _, err := levelEvolution(chain, 29); err == nil {
+		t.Errorf("expected pichu not to evolve at level 29")
+	}
+	if species, err := levelEvolution(chain, 30); err != nil || species != "fakechu" {
+		t.Errorf("expected fakechu at level 30, got %q, %v", species, err)
+	}
+	tyrogue := `{"species":{"name":"tyrogue"},"evolves_to":[
+		{"species":{"name":"hitmonlee"},"evolution_details":[{"trigger":{"name":"level-up"},"min_level":20,"relative_physical_stats":1}]},
+		{"species":{"name":"hitmontop"},"evolution_details":[{"trigger":{"name":"level-up"},"min_level":20,"relative_physical_stats":0}]}]}`
+	var tyrogueChain pokeapi.ChainLink
+	if err := json.Unmarshal([]byte(tyrogue), &tyrogueChain); err != nil {
+		t.Fatal(err)
+	}
+	if species, err := levelEvolution(tyrogueChain, 30); err == nil {
+		t.Errorf("expected stat-dependent evolutions not to qualify, got %q", species)
+	} else if !strings.Contains(err.Error(), "hitmontop needs level 20 with attack equal to defense") {
+		t.Errorf("unexpected error %v", err)
+	}
+	pikachu, _ := findLink(chain, "pikachu")
+	if _, err := levelEvolution(pikachu, 100); err == nil {
+		t.Errorf("expected item evolutions not to qualify")
+	}
+}
+
+// mapSource serves canned bodies keyed by URL, 404ing the rest.
+type mapSource map[string]string
+
+func (s mapSource) Fetch(_ context.Context, url string, _ pokecache.Validators) ([]byte, pokecache.Validators, error) {
+	body, ok := s[url]
+	if !ok {
+		return nil, pokecache.Validators{}, &pokeapi.HTTPError{StatusCode: 404, URL: url}
+	}
+	return []byte(body), pokecache.Validators{}, nil
+}
+
+func newEvolveConfig() *config {
+	source := mapSource{
+		"/api/v2/pokemon-species/honedge":  `{"name":"honedge","evolution_chain":{"url":"/api/v2/evolution-chain/1/"}}`,
+		"/api/v2/evolution-chain/1/":       `{"chain":{"species":{"name":"honedge"},"evolves_to":[{"species":{"name":"doublade"},"evolution_details":[{"trigger":{"name":"level-up"},"min_level":35}],"evolves_to":[]}]}}`,
+		"/api/v2/pokemon-species/doublade": `{"name":"doublade","varieties":[{"is_default":true,"pokemon":{"name":"doublade-shield"}}]}`,
+		"/api/v2/pokemon/doublade-shield":  `{"name":"doublade-shield","species":{"name":"doublade"}}`,
+	}
+	return &config{
+		client: pokeapi.NewClientWithSource("/api/v2", source, nil),
+		pokedex: map[string]caughtPokemon{
+			"honedge": {Pokemon: pokeapi.PokemonAPIResponse{Name: "honedge"}, Level: 40},
+		},
+	}
+}
+
+func TestEvolveUsesDefaultVariety(t *testing.T) {
+	config := newEvolveConfig()
+	if _, err := commandEvolve(config, parseArgs([]string{"honedge"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, ok := config.pokedex["doublade-shield"]; !ok || got.Level != 40 || len(config.pokedex) != 1 {
+		t.Errorf("expected honedge to become doublade-shield, got %+v", config.pokedex)
+	}
+}
+
+func TestEvolveKeepsCaughtEvolution(t *testing.T) {
+	config := newEvolveConfig()
+	config.pokedex["doublade-shield"] = caughtPokemon{Pokemon: pokeapi.PokemonAPIResponse{Name: "doublade-shield"}, Level: 50}
+	if _, err := commandEvolve(config, parseArgs([]string{"honedge"})); err == nil {
+		t.Fatalf("expected the evolve to be refused")
+	}
+	if config.pokedex["honedge"].Level != 40 || config.pokedex["doublade-shield"].Level != 50 {
+		t.Errorf("expected both pokemon to be kept, got %+v", config.pokedex)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type gameResult struct {
+	Game string `json:"game"`
+}
+
+func (r gameResult) WriteText(w io.Writer) error {
+	if r.Game == "" {
+		_, err := fmt.Fprintln(w, "Using the encounters of every game version")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Using the encounters of %s\n", r.Game)
+	return err
+}
+
+// commandGame shows the selected game version, or selects one for the rest
+// of the session. "all" clears the selection.
+func commandGame(config *config, args commandArgs) (any, error) {
+	input := strings.Join(args.positional, " ")
+	switch strings.ToLower(input) {
+	case "":
+		return gameResult{Game: config.game}, nil
+	case "all":
+		config.game = ""
+		return gameResult{}, nil
+	}
+	game, err := resolveName(config, "version", "game version", input)
+	if err != nil {
+		return nil, err
+	}
+	config.game = game
+	return gameResult{Game: game}, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// historySize is how many lines the history file keeps.
+const historySize = 1000
+
+type historyEntry struct {
+	Number  int    `json:"number"`
+	Command string `json:"command"`
+}
+
+type historyResult struct {
+	Entries []historyEntry `json:"entries"`
+}
+
+func (r historyResult) WriteText(w io.Writer) error {
+	for _, entry := range r.Entries {
+		fmt.Fprintf(w, "%5d  %s\n", entry.Number, entry.Command)
+	}
+	return nil
+}
+
+func (r historyResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Entries))
+	for _, entry := range r.Entries {
+		rows = append(rows, []string{strconv.Itoa(entry.Number), entry.Command})
+	}
+	return []string{"number", "command"}, rows
+}
+
+func commandHistory(config *config, args commandArgs) (any, error) {
+	if config.history == nil {
+		return nil, errors.New("history is disabled")
+	}
+	entries := config.history.Entries()
+	if args.arg(0) == "" {
+		res := historyResult{Entries: []historyEntry{}}
+		for i, entry := range entries {
+			res.Entries = append(res.Entries, historyEntry{Number: i + 1, Command: entry})
+		}
+		return res, nil
+	}
+	n, err := strconv.Atoi(args.arg(0))
+	if err != nil || n < 1 || n > len(entries) {
+		return nil, fmt.Errorf("no history entry %q, history lists them", args.arg(0))
+	}
+	// a replayed entry that re-runs history could loop forever
+	if config.replaying {
+		return nil, errors.New("history can't re-run entries from a replayed entry")
+	}
+	line := entries[n-1]
+	if config.interactive {
+		fmt.Println(line)
+		// remember what ran rather than "history <n>"
+		config.history.ReplaceLast(line)
+	}
+	config.replaying = true
+	defer func() { config.replaying = false }()
+	return nil, runLine(config, line)
+}
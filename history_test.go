@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/lineedit"
+)
+
+func TestCommandHistory(t *testing.T) {
+	cfg := &config{history: lineedit.NewHistory(0)}
+	cfg.history.Add("pokedex")
+	cfg.history.Add("history 1")
+	cfg.history.Add("history")
+	cfg.history.Add("pokedex; history 4")
+
+	res, err := commandHistory(cfg, parseArgs(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := res.(historyResult).Entries
+	if len(entries) != 4 || entries[0] != (historyEntry{Number: 1, Command: "pokedex"}) {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	for _, arg := range []string{"0", "5", "one"} {
+		if _, err := commandHistory(cfg, parseArgs([]string{arg})); err == nil {
+			t.Errorf("expected an error for entry %q", arg)
+		}
+	}
+	for _, arg := range []string{"2", "4"} {
+		if _, err := commandHistory(cfg, parseArgs([]string{arg})); err == nil {
+			t.Errorf("expected re-running entry %s, which runs history, to fail", arg)
+		}
+	}
+	if cfg.replaying {
+		t.Errorf("expected replaying to be reset")
+	}
+}
@@ -0,0 +1,187 @@
+// Package battle runs turn-based fights between two pokemon using the
+// main-series stat and damage formulas, without IVs, EVs or natures.
+package battle
+
+import (
+	"math/rand"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+type Move struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Power    int    `json:"power"`
+	Accuracy int    `json:"accuracy"` // 0 means the move never misses
+	Physical bool   `json:"physical"`
+}
+
+// Tackle is used by pokemon that know no damaging move.
+var Tackle = Move{Name: "tackle", Type: "normal", Power: 40, Accuracy: 100, Physical: true}
+
+func NewMove(move pokeapi.MoveAPIResponse) Move {
+	return Move{
+		Name:     move.Name,
+		Type:     move.Type.Name,
+		Power:    move.Power,
+		Accuracy: move.Accuracy,
+		Physical: move.DamageClass.Name == "physical",
+	}
+}
+
+type Combatant struct {
+	Name      string
+	Level     int
+	Types     []string
+	MaxHP     int
+	HP        int
+	Attack    int
+	Defense   int
+	SpAttack  int
+	SpDefense int
+	Speed     int
+	Moves     []Move
+}
+
+// NewCombatant computes the stats of pokemon at level from its base stats.
+func NewCombatant(pokemon pokeapi.PokemonAPIResponse, level int, moves []Move) *Combatant {
+	c := &Combatant{Name: pokemon.Name, Level: level, Moves: moves}
+	if len(c.Moves) == 0 {
+		c.Moves = []Move{Tackle}
+	}
+	for _, t := range pokemon.Types {
+		c.Types = append(c.Types, t.Type.Name)
+	}
+	for _, s := range pokemon.Stats {
+		value := 2 * s.BaseStat * level / 100
+		switch s.Stat.Name {
+		case "hp":
+			c.MaxHP = value + level + 10
+		case "attack":
+			c.Attack = value + 5
+		case "defense":
+			c.Defense = value + 5
+		case "special-attack":
+			c.SpAttack = value + 5
+		case "special-defense":
+			c.SpDefense = value + 5
+		case "speed":
+			c.Speed = value + 5
+		}
+	}
+	c.HP = c.MaxHP
+	return c
+}
+
+func (c *Combatant) Fainted() bool {
+	return c.HP <= 0
+}
+
+func (c *Combatant) Move(name string) (Move, bool) {
+	for _, move := range c.Moves {
+		if move.Name == name {
+			return move, true
+		}
+	}
+	return Move{}, false
+}
+
+// EffectivenessFunc returns the damage multiplier of a move type against the
+// types of the defender.
+type EffectivenessFunc func(moveType string, defenderTypes []string) float64
+
+func neutral(string, []string) float64 { return 1 }
+
+type Battle struct {
+	Player        *Combatant
+	Wild          *Combatant
+	Effectiveness EffectivenessFunc
+	rng           *rand.Rand
+}
+
+// New starts a battle. A nil rng uses a randomly seeded source.
+func New(player, wild *Combatant, rng *rand.Rand) *Battle {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	return &Battle{Player: player, Wild: wild, Effectiveness: neutral, rng: rng}
+}
+
+type Event struct {
+	Attacker      string  `json:"attacker"`
+	Defender      string  `json:"defender"`
+	Move          string  `json:"move"`
+	Missed        bool    `json:"missed"`
+	Damage        int     `json:"damage"`
+	Effectiveness float64 `json:"effectiveness"`
+	Fainted       bool    `json:"fainted"`
+}
+
+func (b *Battle) Over() bool {
+	return b.Player.Fainted() || b.Wild.Fainted()
+}
+
+// Turn plays one round: the player uses playerMove and the wild pokemon a
+// random move of its own, the faster one first. The round stops as soon as
+// one side faints.
+func (b *Battle) Turn(playerMove Move) []Event {
+	wildMove := b.Wild.Moves[b.rng.Intn(len(b.Wild.Moves))]
+	first, second := b.Player, b.Wild
+	firstMove, secondMove := playerMove, wildMove
+	if b.Wild.Speed > b.Player.Speed || (b.Wild.Speed == b.Player.Speed && b.rng.Intn(2) == 0) {
+		first, second = second, first
+		firstMove, secondMove = secondMove, firstMove
+	}
+	events := []Event{b.attack(first, second, firstMove)}
+	if !second.Fainted() {
+		events = append(events, b.attack(second, first, secondMove))
+	}
+	return events
+}
+
+func (b *Battle) attack(attacker, defender *Combatant, move Move) Event {
+	event := Event{Attacker: attacker.Name, Defender: defender.Name, Move: move.Name}
+	if move.Accuracy > 0 && b.rng.Intn(100) >= move.Accuracy {
+		event.Missed = true
+		return event
+	}
+	event.Effectiveness = b.Effectiveness(move.Type, defender.Types)
+	event.Damage = b.damage(attacker, defender, move, event.Effectiveness)
+	defender.HP = max(defender.HP-event.Damage, 0)
+	event.Fainted = defender.Fainted()
+	return event
+}
+
+// damage applies the main-series formula with STAB, type effectiveness and
+// the 85-100% random factor.
+func (b *Battle) damage(attacker, defender *Combatant, move Move, effectiveness float64) int {
+	attack, defense := attacker.SpAttack, defender.SpDefense
+	if move.Physical {
+		attack, defense = attacker.Attack, defender.Defense
+	}
+	if defense <= 0 {
+		defense = 1
+	}
+	base := float64((2*attacker.Level/5+2)*move.Power*attack/defense)/50 + 2
+	modifier := effectiveness * float64(85+b.rng.Intn(16)) / 100
+	for _, t := range attacker.Types {
+		if t == move.Type {
+			modifier *= 1.5
+			break
+		}
+	}
+	damage := int(base * modifier)
+	if damage < 1 && effectiveness > 0 {
+		damage = 1
+	}
+	return damage
+}
+
+// CatchBonus is the catch rate multiplier earned by weakening the wild
+// pokemon: 1 at full health, up to 3 as its HP nears zero.
+func (b *Battle) CatchBonus() float64 {
+	if b.Wild.MaxHP <= 0 {
+		return 1
+	}
+	return float64(3*b.Wild.MaxHP-2*b.Wild.HP) / float64(b.Wild.MaxHP)
+}
@@ -0,0 +1,69 @@
+package battle
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+func testPokemon(t *testing.T, data string) pokeapi.PokemonAPIResponse {
+	t.Helper()
+	var pokemon pokeapi.PokemonAPIResponse
+	if err := json.Unmarshal([]byte(data), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	return pokemon
+}
+
+const pikachu = `{"name":"pikachu","types":[{"type":{"name":"electric"}}],"stats":[
+	{"base_stat":35,"stat":{"name":"hp"}},{"base_stat":55,"stat":{"name":"attack"}},
+	{"base_stat":40,"stat":{"name":"defense"}},{"base_stat":50,"stat":{"name":"special-attack"}},
+	{"base_stat":50,"stat":{"name":"special-defense"}},{"base_stat":90,"stat":{"name":"speed"}}]}`
+
+const geodude = `{"name":"geodude","types":[{"type":{"name":"rock"}},{"type":{"name":"ground"}}],"stats":[
+	{"base_stat":40,"stat":{"name":"hp"}},{"base_stat":80,"stat":{"name":"attack"}},
+	{"base_stat":100,"stat":{"name":"defense"}},{"base_stat":30,"stat":{"name":"special-attack"}},
+	{"base_stat":30,"stat":{"name":"special-defense"}},{"base_stat":20,"stat":{"name":"speed"}}]}`
+
+func TestNewCombatantStats(t *testing.T) {
+	c := NewCombatant(testPokemon(t, pikachu), 50, nil)
+	if c.MaxHP != 95 || c.HP != 95 {
+		t.Errorf("expected 95 HP, got %d/%d", c.HP, c.MaxHP)
+	}
+	if c.Speed != 95 {
+		t.Errorf("expected 95 speed, got %d", c.Speed)
+	}
+	if len(c.Moves) != 1 || c.Moves[0] != Tackle {
+		t.Errorf("expected tackle as the fallback move, got %+v", c.Moves)
+	}
+}
+
+func TestTurnAndCatchBonus(t *testing.T) {
+	thunderShock := Move{Name: "thunder-shock", Type: "electric", Power: 40}
+	player := NewCombatant(testPokemon(t, pikachu), 30, []Move{thunderShock})
+	wild := NewCombatant(testPokemon(t, geodude), 40, nil)
+	b := New(player, wild, rand.New(rand.NewSource(1)))
+
+	if bonus := b.CatchBonus(); bonus != 1 {
+		t.Errorf("expected no bonus at full HP, got %v", bonus)
+	}
+	events := b.Turn(thunderShock)
+	if events[0].Attacker != "pikachu" {
+		t.Errorf("expected the faster pokemon to attack first, got %s", events[0].Attacker)
+	}
+	if events[0].Damage <= 0 || wild.HP != wild.MaxHP-events[0].Damage {
+		t.Errorf("expected damage to be applied, got %+v with %d/%d HP", events[0], wild.HP, wild.MaxHP)
+	}
+	if b.CatchBonus() <= 1 {
+		t.Errorf("expected a bonus once the wild pokemon is hurt, got %v", b.CatchBonus())
+	}
+
+	b.Effectiveness = func(string, []string) float64 { return 0 }
+	hp := wild.HP
+	events = b.Turn(thunderShock)
+	if events[0].Damage != 0 || wild.HP != hp {
+		t.Errorf("expected immune defender to take no damage, got %+v", events[0])
+	}
+}
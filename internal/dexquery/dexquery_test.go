@@ -0,0 +1,109 @@
+package dexquery
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func subject(t *testing.T, level int, data string) Subject {
+	t.Helper()
+	s := Subject{Level: level}
+	if err := json.Unmarshal([]byte(data), &s.Pokemon); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testSubjects(t *testing.T) []Subject {
+	return []Subject{
+		subject(t, 5, `{"name":"pikachu","base_experience":112,"height":4,"weight":60,
+			"types":[{"type":{"name":"electric"}}],"abilities":[{"ability":{"name":"static"}}],
+			"stats":[{"base_stat":35,"stat":{"name":"hp"}},{"base_stat":90,"stat":{"name":"speed"}}]}`),
+		subject(t, 25, `{"name":"tentacool","base_experience":67,"height":9,"weight":455,
+			"types":[{"type":{"name":"water"}},{"type":{"name":"poison"}}],"abilities":[{"ability":{"name":"clear-body"}}],
+			"stats":[{"base_stat":40,"stat":{"name":"hp"}},{"base_stat":70,"stat":{"name":"speed"}}]}`),
+		subject(t, 30, `{"name":"tentacruel","base_experience":180,"height":16,"weight":550,
+			"types":[{"type":{"name":"water"}},{"type":{"name":"poison"}}],"abilities":[{"ability":{"name":"clear-body"}}],
+			"stats":[{"base_stat":80,"stat":{"name":"hp"}},{"base_stat":100,"stat":{"name":"speed"}}]}`),
+		subject(t, 12, `{"name":"psyduck","base_experience":64,"height":8,"weight":196,
+			"types":[{"type":{"name":"water"}}],"abilities":[{"ability":{"name":"damp"}}],
+			"stats":[{"base_stat":50,"stat":{"name":"hp"}},{"base_stat":55,"stat":{"name":"speed"}}]}`),
+	}
+}
+
+func names(subjects []Subject) []string {
+	out := []string{}
+	for _, s := range subjects {
+		out = append(out, s.Pokemon.Name)
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"pikachu", "psyduck", "tentacool", "tentacruel"}},
+		{"type:water", []string{"psyduck", "tentacool", "tentacruel"}},
+		{"type:water stat.speed>60", []string{"tentacool", "tentacruel"}},
+		{"type:water,electric sort:-base_experience limit:2", []string{"tentacruel", "pikachu"}},
+		{"-type:poison", []string{"pikachu", "psyduck"}},
+		{"type!=water", []string{"pikachu"}},
+		{"name:tenta*", []string{"tentacool", "tentacruel"}},
+		{"ability:clear-body level>=30", []string{"tentacruel"}},
+		{"stat.total<=110 sort:-stat.total", []string{"tentacool", "psyduck"}},
+		{"weight:60,455 sort:-name", []string{"tentacool", "pikachu"}},
+		{"TYPE:Water height<9", []string{"psyduck"}},
+	}
+	for _, c := range cases {
+		q, err := Parse(c.query)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", c.query, err)
+			continue
+		}
+		if got := names(q.Apply(testSubjects(t))); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q matched %v, want %v", c.query, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		query   string
+		message string
+		column  int
+	}{
+		{"typ:water", `unknown field "typ", did you mean type?`, 0},
+		{"stat.sped>80", `unknown stat "sped", did you mean speed?`, 0},
+		{"type>water", "type is a list, it cannot be compared with >", 4},
+		{"stat.speed>fast", `stat.speed is a number, got "fast"`, 11},
+		{"type:water stat.speed", "expected an operator like ':' or '>' after stat.speed, got the end of the query", 21},
+		{"limit:0", `limit must be a positive number, got "0"`, 6},
+		{"sort:type", "cannot sort by type, it is a list", 5},
+		{"level>5,6", "> takes a single value", 7},
+		{"type:water & level>5", `unexpected character '&'`, 11},
+		{"level!5", "expected '=' after '!'", 5},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.query)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q): expected a SyntaxError, got %v", c.query, err)
+			continue
+		}
+		if syntaxErr.Msg != c.message || syntaxErr.Pos != c.column {
+			t.Errorf("Parse(%q) = %q at %d, want %q at %d", c.query, syntaxErr.Msg, syntaxErr.Pos, c.message, c.column)
+		}
+	}
+}
+
+func TestSyntaxErrorPointsAtColumn(t *testing.T) {
+	_, err := Parse("type:water stat.sped>80")
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 || lines[2] != "  "+strings.Repeat(" ", 11)+"^" {
+		t.Errorf("unexpected error layout %q", err.Error())
+	}
+}
@@ -0,0 +1,121 @@
+package dexquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/fuzzy"
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+// Subject is a caught pokemon as queries see it.
+type Subject struct {
+	Pokemon pokeapi.PokemonAPIResponse
+	Level   int
+	Area    string
+}
+
+type fieldKind int
+
+const (
+	numberField fieldKind = iota
+	textField
+	listField
+)
+
+func (k fieldKind) String() string {
+	switch k {
+	case numberField:
+		return "a number"
+	case textField:
+		return "a name"
+	default:
+		return "a list"
+	}
+}
+
+// field reads one property of a Subject; only the accessor matching kind is
+// set.
+type field struct {
+	name   string
+	kind   fieldKind
+	number func(Subject) int
+	text   func(Subject) string
+	list   func(Subject) []string
+}
+
+var fields = map[string]field{
+	"name": {kind: textField, text: func(s Subject) string { return s.Pokemon.Name }},
+	"area": {kind: textField, text: func(s Subject) string { return s.Area }},
+	"type": {kind: listField, list: func(s Subject) []string {
+		types := make([]string, len(s.Pokemon.Types))
+		for i, t := range s.Pokemon.Types {
+			types[i] = t.Type.Name
+		}
+		return types
+	}},
+	"ability": {kind: listField, list: func(s Subject) []string {
+		abilities := make([]string, len(s.Pokemon.Abilities))
+		for i, a := range s.Pokemon.Abilities {
+			abilities[i] = a.Ability.Name
+		}
+		return abilities
+	}},
+	"height":          {kind: numberField, number: func(s Subject) int { return s.Pokemon.Height }},
+	"weight":          {kind: numberField, number: func(s Subject) int { return s.Pokemon.Weight }},
+	"base_experience": {kind: numberField, number: func(s Subject) int { return s.Pokemon.BaseExperience }},
+	"level":           {kind: numberField, number: func(s Subject) int { return s.Level }},
+}
+
+// statNames are the stats reachable as stat.<name>, total being their sum.
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed", "total"}
+
+func statField(stat string) field {
+	return field{kind: numberField, number: func(s Subject) int {
+		sum := 0
+		for _, value := range s.Pokemon.Stats {
+			if value.Stat.Name == stat {
+				return value.BaseStat
+			}
+			sum += value.BaseStat
+		}
+		if stat == "total" {
+			return sum
+		}
+		return 0
+	}}
+}
+
+// lookupField resolves a field name, erring with suggestions when unknown.
+func lookupField(name string) (field, error) {
+	name = strings.ToLower(name)
+	if stat, ok := strings.CutPrefix(name, "stat."); ok {
+		for _, known := range statNames {
+			if stat == known {
+				f := statField(stat)
+				f.name = name
+				return f, nil
+			}
+		}
+		return field{}, fmt.Errorf("unknown stat %q%s", stat, suggest(stat, statNames))
+	}
+	if f, ok := fields[name]; ok {
+		f.name = name
+		return f, nil
+	}
+	var known []string
+	for field := range fields {
+		known = append(known, field)
+	}
+	for _, stat := range statNames {
+		known = append(known, "stat."+stat)
+	}
+	return field{}, fmt.Errorf("unknown field %q%s", name, suggest(name, known))
+}
+
+func suggest(name string, known []string) string {
+	if ranked := fuzzy.Rank(name, known); len(ranked) > 0 {
+		return ", did you mean " + ranked[0] + "?"
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package dexquery
+
+import (
+	"fmt"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokOp
+	tokComma
+	tokMinus
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokWord:
+		return "a word"
+	case tokOp:
+		return "an operator"
+	case tokComma:
+		return "','"
+	case tokMinus:
+		return "'-'"
+	default:
+		return "the end of the query"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the byte offset of the token in the query
+	pos int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return t.kind.String()
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func isWordRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '*' || r == '_' {
+		return true
+	}
+	// a leading '-' negates a term or reverses a sort key instead
+	return !first && (r == '-' || r == '.')
+}
+
+// lex splits a query into tokens. Whitespace only separates tokens.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	// offsets maps rune indexes to byte offsets, for error positions
+	offsets := make([]int, len(runes)+1)
+	offset := 0
+	for i, r := range runes {
+		offsets[i] = offset
+		offset += len(string(r))
+	}
+	offsets[len(runes)] = offset
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == ',':
+			i++
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: offsets[start]})
+		case r == '-':
+			i++
+			tokens = append(tokens, token{kind: tokMinus, text: "-", pos: offsets[start]})
+		case r == ':' || r == '=':
+			i++
+			tokens = append(tokens, token{kind: tokOp, text: string(r), pos: offsets[start]})
+		case r == '<' || r == '>' || r == '!':
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			} else if r == '!' {
+				return nil, &SyntaxError{Query: query, Pos: offsets[start], Msg: "expected '=' after '!'"}
+			}
+			tokens = append(tokens, token{kind: tokOp, text: string(runes[start:i]), pos: offsets[start]})
+		case isWordRune(r, true):
+			for i < len(runes) && isWordRune(runes[i], false) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokWord, text: string(runes[start:i]), pos: offsets[start]})
+		default:
+			return nil, &SyntaxError{Query: query, Pos: offsets[start], Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(query)}), nil
+}
@@ -0,0 +1,199 @@
+// Package dexquery filters and sorts caught pokemon with queries like
+//
+//	type:water stat.speed>80 sort:-base_experience limit:10
+//
+// A query is a list of terms that must all hold. A term compares a field
+// with one or more comma separated values: ':' and '=' match any of them,
+// '!=' none of them, and '<', '<=', '>', '>=' compare numbers. Names may use
+// '*' as a wildcard, and a leading '-' negates a term. sort: orders by
+// fields, descending when prefixed with '-', and limit: keeps the first n.
+package dexquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError reports a bad query and where in it things went wrong.
+type SyntaxError struct {
+	Query string
+	// Pos is the byte offset the error points at
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	column := utf8.RuneCountInString(e.Query[:e.Pos])
+	return fmt.Sprintf("bad query: %s\n  %s\n  %s^", e.Msg, e.Query, strings.Repeat(" ", column))
+}
+
+type filter struct {
+	field  field
+	op     string
+	values []string
+	// numbers holds values parsed, for number fields
+	numbers []int
+	negate  bool
+}
+
+type sortKey struct {
+	field field
+	desc  bool
+}
+
+// Query is a parsed query, ready to Apply.
+type Query struct {
+	filters []filter
+	sorts   []sortKey
+	limit   int
+}
+
+type parser struct {
+	query  string
+	tokens []token
+	next   int
+}
+
+// Parse parses a query. An empty query matches everything.
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{query: query, tokens: tokens}
+	q := &Query{}
+	for p.peek().kind != tokEOF {
+		if err := p.term(q); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &SyntaxError{Query: p.query, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.advance()
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s, got %s", what, t)
+	}
+	return t, nil
+}
+
+func (p *parser) term(q *Query) error {
+	negate := false
+	if p.peek().kind == tokMinus {
+		p.advance()
+		negate = true
+	}
+	name, err := p.expect(tokWord, "a field like type or stat.speed")
+	if err != nil {
+		return err
+	}
+	op, err := p.expect(tokOp, "an operator like ':' or '>' after "+name.text)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(name.text) {
+	case "sort":
+		if negate || op.text != ":" {
+			return p.errorf(name, "sort is written sort:<field>, or sort:-<field> to reverse it")
+		}
+		return p.sort(q)
+	case "limit":
+		if negate || (op.text != ":" && op.text != "=") {
+			return p.errorf(name, "limit is written limit:<n>")
+		}
+		return p.limit(q)
+	}
+	f, err := lookupField(name.text)
+	if err != nil {
+		return p.errorf(name, "%s", err)
+	}
+	ordering := op.text != ":" && op.text != "=" && op.text != "!="
+	if ordering && f.kind != numberField {
+		return p.errorf(op, "%s is %s, it cannot be compared with %s", f.name, f.kind, op.text)
+	}
+	cond := filter{field: f, op: op.text, negate: negate}
+	for {
+		value, err := p.expect(tokWord, "a value for "+f.name)
+		if err != nil {
+			return err
+		}
+		if f.kind == numberField {
+			n, err := strconv.Atoi(value.text)
+			if err != nil {
+				return p.errorf(value, "%s is a number, got %q", f.name, value.text)
+			}
+			cond.numbers = append(cond.numbers, n)
+		}
+		cond.values = append(cond.values, strings.ToLower(value.text))
+		if p.peek().kind != tokComma {
+			break
+		}
+		comma := p.advance()
+		if ordering {
+			return p.errorf(comma, "%s takes a single value", op.text)
+		}
+	}
+	q.filters = append(q.filters, cond)
+	return nil
+}
+
+func (p *parser) sort(q *Query) error {
+	for {
+		desc := false
+		if p.peek().kind == tokMinus {
+			p.advance()
+			desc = true
+		}
+		name, err := p.expect(tokWord, "a field to sort by")
+		if err != nil {
+			return err
+		}
+		f, err := lookupField(name.text)
+		if err != nil {
+			return p.errorf(name, "%s", err)
+		}
+		if f.kind == listField {
+			return p.errorf(name, "cannot sort by %s, it is a list", f.name)
+		}
+		q.sorts = append(q.sorts, sortKey{field: f, desc: desc})
+		if p.peek().kind != tokComma {
+			return nil
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) limit(q *Query) error {
+	value, err := p.expect(tokWord, "the number of pokemon to keep")
+	if err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(value.text)
+	if err != nil || n < 1 {
+		return p.errorf(value, "limit must be a positive number, got %q", value.text)
+	}
+	if q.limit != 0 {
+		return p.errorf(value, "limit is given twice")
+	}
+	q.limit = n
+	return nil
+}
@@ -0,0 +1,86 @@
+package dexquery
+
+import (
+	"cmp"
+	"path"
+	"slices"
+)
+
+// Apply returns the subjects matching every term of q, sorted by q's sort
+// keys, then by name, and cut to its limit.
+func (q *Query) Apply(subjects []Subject) []Subject {
+	var matched []Subject
+	for _, subject := range subjects {
+		if q.matches(subject) {
+			matched = append(matched, subject)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b Subject) int {
+		for _, key := range q.sorts {
+			var c int
+			if key.field.kind == numberField {
+				c = cmp.Compare(key.field.number(a), key.field.number(b))
+			} else {
+				c = cmp.Compare(key.field.text(a), key.field.text(b))
+			}
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Pokemon.Name, b.Pokemon.Name)
+	})
+	if q.limit > 0 && len(matched) > q.limit {
+		matched = matched[:q.limit]
+	}
+	return matched
+}
+
+func (q *Query) matches(subject Subject) bool {
+	for _, f := range q.filters {
+		if f.matches(subject) == f.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (f filter) matches(subject Subject) bool {
+	switch f.field.kind {
+	case numberField:
+		value := f.field.number(subject)
+		switch f.op {
+		case "<":
+			return value < f.numbers[0]
+		case "<=":
+			return value <= f.numbers[0]
+		case ">":
+			return value > f.numbers[0]
+		case ">=":
+			return value >= f.numbers[0]
+		case "!=":
+			return !slices.Contains(f.numbers, value)
+		default:
+			return slices.Contains(f.numbers, value)
+		}
+	case textField:
+		return f.anyMatch([]string{f.field.text(subject)}) != (f.op == "!=")
+	default:
+		return f.anyMatch(f.field.list(subject)) != (f.op == "!=")
+	}
+}
+
+// anyMatch reports whether one of the filter's values, which may hold '*'
+// wildcards, matches one of items.
+func (f filter) anyMatch(items []string) bool {
+	for _, pattern := range f.values {
+		for _, item := range items {
+			if ok, _ := path.Match(pattern, item); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// Package fuzzy matches mistyped names against a list of known ones by
+// prefix and edit distance.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions bounds how many names Resolve offers.
+const maxSuggestions = 5
+
+type candidate struct {
+	name  string
+	score int
+}
+
+// Rank returns the names close to query, best first. A name starting with
+// query scores 0; others score their edit distance to query, or to their own
+// first len(query) runes plus one, whichever is lower. Names scoring more than
+// a third of query's length, and at least 1, are left out.
+func Rank(query string, names []string) []string {
+	candidates := rank(query, names)
+	ranked := make([]string, len(candidates))
+	for i, c := range candidates {
+		ranked[i] = c.name
+	}
+	return ranked
+}
+
+func rank(query string, names []string) []candidate {
+	threshold := max(1, utf8.RuneCountInString(query)/3)
+	var candidates []candidate
+	for _, name := range names {
+		if score := score(query, name); score <= threshold {
+			candidates = append(candidates, candidate{name: name, score: score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score < b.score
+		}
+		if len(a.name) != len(b.name) {
+			return len(a.name) < len(b.name)
+		}
+		return a.name < b.name
+	})
+	return candidates
+}
+
+func score(query, name string) int {
+	if strings.HasPrefix(name, query) {
+		return 0
+	}
+	dist := distance(query, name)
+	if head := []rune(name); len(head) > utf8.RuneCountInString(query) {
+		dist = min(dist, distance(query, string(head[:utf8.RuneCountInString(query)]))+1)
+	}
+	return dist
+}
+
+// Resolve finds the name meant by query. An exact match, or a single best
+// candidate, is returned with ok set; otherwise the best few candidates are
+// returned as suggestions, which may be empty.
+func Resolve(query string, names []string) (match string, suggestions []string, ok bool) {
+	for _, name := range names {
+		if name == query {
+			return name, nil, true
+		}
+	}
+	candidates := rank(query, names)
+	if len(candidates) == 1 || (len(candidates) > 1 && candidates[0].score < candidates[1].score) {
+		return candidates[0].name, nil, true
+	}
+	for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+		suggestions = append(suggestions, c.name)
+	}
+	return "", suggestions, false
+}
+
+// distance is the Levenshtein distance between a and b, in runes.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
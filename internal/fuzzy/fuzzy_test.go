@@ -0,0 +1,55 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+var pokemon = []string{"pikachu", "pikachu-rock-star", "pichu", "raichu", "charmander", "charmeleon", "bulbasaur"}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"pikachu", "pikachu", 0},
+		{"café", "cafe", 1},
+	}
+	for _, c := range cases {
+		if got := distance(c.a, c.b); got != c.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		query       string
+		match       string
+		suggestions []string
+		ok          bool
+	}{
+		{"pikachu", "pikachu", nil, true},
+		{"pikachuu", "pikachu", nil, true},
+		{"chamander", "charmander", nil, true},
+		{"bulba", "bulbasaur", nil, true},
+		{"charm", "", []string{"charmander", "charmeleon"}, false},
+		{"mewtwo", "", nil, false},
+	}
+	for _, c := range cases {
+		match, suggestions, ok := Resolve(c.query, pokemon)
+		if match != c.match || ok != c.ok || !reflect.DeepEqual(suggestions, c.suggestions) {
+			t.Errorf("Resolve(%q) = %q, %v, %v, want %q, %v, %v", c.query, match, suggestions, ok, c.match, c.suggestions, c.ok)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	got := Rank("pika", pokemon)
+	want := []string{"pikachu", "pikachu-rock-star"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank(pika) = %v, want %v", got, want)
+	}
+}
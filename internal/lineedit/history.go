@@ -0,0 +1,108 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// History is the list of lines entered, oldest first, shared between an
+// Editor and whoever lists or persists it.
+type History struct {
+	mu      sync.Mutex
+	entries []string
+	max     int
+}
+
+// NewHistory returns a History keeping at most max entries, or all of them
+// if max is 0.
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+// Add appends line, unless it is blank or repeats the last entry.
+func (h *History) Add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+	h.entries = append(h.entries, line)
+	if h.max > 0 && len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+}
+
+// ReplaceLast swaps the newest entry for line, e.g. to record what a command
+// re-running an older entry actually ran.
+func (h *History) ReplaceLast(line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n := len(h.entries); n > 0 {
+		h.entries[n-1] = line
+	}
+}
+
+// Entries returns a copy of the entries, oldest first.
+func (h *History) Entries() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.entries...)
+}
+
+// Load appends the entries saved in the file at path, one per line. A missing
+// file is not an error.
+func (h *History) Load(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Save writes the entries to the file at path, one per line, through a temp
+// file so a crash never truncates it.
+func (h *History) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var data strings.Builder
+	for _, entry := range h.Entries() {
+		data.WriteString(entry)
+		data.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	// history can hold anything typed, so keep it private
+	if err := os.WriteFile(tmp, []byte(data.String()), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// search returns the index of the newest entry before index from containing
+// query, or -1.
+func (h *History) search(query string, from int) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := min(from, len(h.entries)) - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
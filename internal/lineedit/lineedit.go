@@ -0,0 +1,400 @@
+// Package lineedit reads lines from a terminal with emacs-style editing keys
+// and tab completion. When the input is not a terminal it reads plain lines.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer returns the candidates for the word at the end of head, the part
+// of the line before the cursor, along with the index in head where that
+// word starts. A candidate replaces the word when chosen.
+type Completer func(head string) (start int, candidates []string)
+
+// Editor reads lines from in, echoing and redrawing them on out.
+type Editor struct {
+	in       *os.File
+	out      io.Writer
+	reader   *bufio.Reader
+	complete Completer
+	history  *History
+
+	mu      sync.Mutex
+	restore func() error
+}
+
+// New returns an Editor reading from in. complete may be nil.
+func New(in *os.File, out io.Writer, complete Completer) *Editor {
+	return &Editor{in: in, out: out, reader: bufio.NewReader(in), complete: complete}
+}
+
+// SetHistory lets the up and down keys and Ctrl-R reverse search browse h.
+// ReadLine does not add to it; the caller decides what is worth keeping.
+func (e *Editor) SetHistory(h *History) {
+	e.history = h
+}
+
+// ReadLine prints prompt and returns the line typed, without its newline. It
+// returns io.EOF at the end of input or on Ctrl-D on an empty line, and
+// ErrInterrupted on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	restore, err := rawMode(e.in.Fd())
+	if err != nil {
+		// not a terminal, or one we cannot drive: read a plain line
+		fmt.Fprint(e.out, prompt)
+		return e.readPlain()
+	}
+	e.mu.Lock()
+	e.restore = restore
+	e.mu.Unlock()
+	defer e.Restore()
+	return e.edit(prompt)
+}
+
+// Restore puts the terminal back the way ReadLine found it. It is safe to call
+// from another goroutine, e.g. when a signal ends the program mid-read.
+func (e *Editor) Restore() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.restore == nil {
+		return nil
+	}
+	err := e.restore()
+	e.restore = nil
+	return err
+}
+
+func (e *Editor) readPlain() (string, error) {
+	line, err := e.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyCtrlJ     = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// line is the text being edited and the cursor position in it, in runes.
+type line struct {
+	buf []rune
+	pos int
+}
+
+func (l *line) insert(runes ...rune) {
+	l.buf = append(l.buf[:l.pos], append(runes, l.buf[l.pos:]...)...)
+	l.pos += len(runes)
+}
+
+// replace swaps the runes between from and the cursor for runes.
+func (l *line) replace(from int, runes []rune) {
+	tail := append([]rune(nil), l.buf[l.pos:]...)
+	l.buf = append(append(l.buf[:from], runes...), tail...)
+	l.pos = from + len(runes)
+}
+
+func (l *line) deleteRange(from, to int) {
+	l.buf = append(l.buf[:from], l.buf[to:]...)
+	l.pos = from
+}
+
+// set replaces the whole text, leaving the cursor at its end.
+func (l *line) set(text string) {
+	l.buf = []rune(text)
+	l.pos = len(l.buf)
+}
+
+// edit runs the editing loop until a line is entered. The terminal must be in
+// raw mode.
+func (e *Editor) edit(prompt string) (string, error) {
+	var l line
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(l.buf))
+		if back := len(l.buf) - l.pos; back > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", back)
+		}
+	}
+	redraw()
+	lastWasTab := false
+	// browsing is the history entry shown, len(entries) for the line being
+	// typed, which draft keeps while browsing
+	var entries []string
+	if e.history != nil {
+		entries = e.history.Entries()
+	}
+	browsing, draft := len(entries), ""
+	older := func() {
+		if browsing == 0 {
+			return
+		}
+		if browsing == len(entries) {
+			draft = string(l.buf)
+		}
+		browsing--
+		l.set(entries[browsing])
+	}
+	newer := func() {
+		if browsing == len(entries) {
+			return
+		}
+		browsing++
+		if browsing == len(entries) {
+			l.set(draft)
+		} else {
+			l.set(entries[browsing])
+		}
+	}
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(l.buf) > 0 {
+				fmt.Fprintln(e.out)
+				return string(l.buf), nil
+			}
+			return "", err
+		}
+		wasTab := lastWasTab
+		lastWasTab = r == keyTab
+		switch r {
+		case keyEnter, keyCtrlJ:
+			fmt.Fprintln(e.out)
+			return string(l.buf), nil
+		case keyCtrlC:
+			fmt.Fprintln(e.out, "^C")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				fmt.Fprintln(e.out)
+				return "", io.EOF
+			}
+			if l.pos < len(l.buf) {
+				l.deleteRange(l.pos, l.pos+1)
+			}
+		case keyTab:
+			e.completeWord(&l, wasTab, redraw)
+		case keyBackspace, keyCtrlH:
+			if l.pos > 0 {
+				l.deleteRange(l.pos-1, l.pos)
+			}
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyCtrlB:
+			l.pos = max(l.pos-1, 0)
+		case keyCtrlF:
+			l.pos = min(l.pos+1, len(l.buf))
+		case keyCtrlK:
+			l.buf = l.buf[:l.pos]
+		case keyCtrlU:
+			l.deleteRange(0, l.pos)
+		case keyCtrlW:
+			start := l.pos
+			for start > 0 && l.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && l.buf[start-1] != ' ' {
+				start--
+			}
+			l.deleteRange(start, l.pos)
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			older()
+		case keyCtrlN:
+			newer()
+		case keyCtrlR:
+			if e.history != nil && e.reverseSearch(&l) {
+				fmt.Fprintf(e.out, "\r%s%s\x1b[K\n", prompt, string(l.buf))
+				return string(l.buf), nil
+			}
+		case keyEscape:
+			switch e.escape(&l) {
+			case "A":
+				older()
+			case "B":
+				newer()
+			}
+		default:
+			if r >= ' ' {
+				l.insert(r)
+			}
+		}
+		redraw()
+	}
+}
+
+// escape handles the cursor keys sent as escape sequences, ESC [ or ESC O
+// followed by optional digits and a final letter or '~', and returns the
+// sequence after the introducer for the caller to handle the rest.
+func (e *Editor) escape(l *line) string {
+	intro, _, err := e.reader.ReadRune()
+	if err != nil || (intro != '[' && intro != 'O') {
+		return ""
+	}
+	var seq strings.Builder
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq.WriteRune(r)
+		if r < '0' || r > '9' {
+			break
+		}
+	}
+	switch seq.String() {
+	case "C":
+		l.pos = min(l.pos+1, len(l.buf))
+	case "D":
+		l.pos = max(l.pos-1, 0)
+	case "H", "1~", "7~":
+		l.pos = 0
+	case "F", "4~", "8~":
+		l.pos = len(l.buf)
+	case "3~":
+		if l.pos < len(l.buf) {
+			l.deleteRange(l.pos, l.pos+1)
+		}
+	}
+	return seq.String()
+}
+
+// reverseSearch runs a Ctrl-R search through the history: typing narrows the
+// query, Ctrl-R finds an older match, Ctrl-G or Ctrl-C gives up and restores
+// the line. Any other key takes the match into the line and is then handled
+// as usual, except Enter, for which reverseSearch reports true to have the
+// match run at once.
+func (e *Editor) reverseSearch(l *line) bool {
+	original := string(l.buf)
+	newest := len(e.history.Entries())
+	var query []rune
+	match := -1
+	found := ""
+	for {
+		label := "reverse-i-search"
+		if match < 0 && len(query) > 0 {
+			label = "failed reverse-i-search"
+		}
+		fmt.Fprintf(e.out, "\r(%s)`%s': %s\x1b[K", label, string(query), found)
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			l.set(found)
+			return false
+		}
+		switch {
+		case r == keyCtrlR:
+			if match > 0 {
+				if older := e.history.search(string(query), match); older >= 0 {
+					match = older
+				}
+			}
+		case r == keyBackspace || r == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = -1
+				if len(query) > 0 {
+					match = e.history.search(string(query), newest)
+				}
+			}
+		case r == keyCtrlG || r == keyCtrlC:
+			l.set(original)
+			return false
+		case r == keyEnter || r == keyCtrlJ:
+			l.set(found)
+			return true
+		case r < ' ':
+			l.set(found)
+			e.reader.UnreadRune()
+			return false
+		default:
+			query = append(query, r)
+			// the current match may still fit the longer query
+			from := newest
+			if match >= 0 {
+				from = match + 1
+			}
+			match = e.history.search(string(query), from)
+		}
+		if match >= 0 {
+			found = e.history.Entries()[match]
+		} else if len(query) == 0 {
+			found = ""
+		}
+	}
+}
+
+// completeWord completes the word before the cursor: a single candidate is
+// inserted whole, several are narrowed to their common prefix, and a second
+// Tab that cannot narrow them further lists them.
+func (e *Editor) completeWord(l *line, secondTab bool, redraw func()) {
+	if e.complete == nil {
+		return
+	}
+	head := string(l.buf[:l.pos])
+	start, candidates := e.complete(head)
+	if start < 0 || start > len(head) {
+		return
+	}
+	word := head[start:]
+	from := utf8.RuneCountInString(head[:start])
+	switch {
+	case len(candidates) == 0:
+		fmt.Fprint(e.out, "\a")
+	case len(candidates) == 1:
+		l.replace(from, []rune(candidates[0]+" "))
+	default:
+		prefix := commonPrefix(candidates)
+		if len(prefix) > len(word) {
+			l.replace(from, []rune(prefix))
+		} else if secondTab {
+			sorted := append([]string(nil), candidates...)
+			sort.Strings(sorted)
+			fmt.Fprintf(e.out, "\n%s\n", strings.Join(sorted, "  "))
+		} else {
+			fmt.Fprint(e.out, "\a")
+		}
+	}
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,159 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, complete Completer) (*Editor, *strings.Builder) {
+	out := &strings.Builder{}
+	return &Editor{reader: bufio.NewReader(strings.NewReader(input)), out: out, complete: complete}, out
+}
+
+func prefixCompleter(words ...string) Completer {
+	return func(head string) (int, []string) {
+		start := strings.LastIndex(head, " ") + 1
+		var matches []string
+		for _, word := range words {
+			if strings.HasPrefix(word, head[start:]) {
+				matches = append(matches, word)
+			}
+		}
+		return start, matches
+	}
+}
+
+func TestEditKeys(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "map\r", "map"},
+		{"backspace", "mapx\x7f\r", "map"},
+		{"insert after moving left", "mp\x1b[Da\r", "map"},
+		{"home and end", "ap\x01m\x05!\r", "map!"},
+		{"kill to end", "map extra\x01\x06\x06\x06\x0b\r", "map"},
+		{"delete word", "explore canalave\x17\r", "explore "},
+		{"delete key", "mapp\x1b[D\x1b[3~\r", "map"},
+		{"unicode", "café\x7fe\r", "cafe"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, _ := newTestEditor(c.input, nil)
+			got, err := e.edit("> ")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestEditCompletion(t *testing.T) {
+	complete := prefixCompleter("pikachu", "pidgey", "pidgeotto", "tentacool")
+
+	e, _ := newTestEditor("catch te\t\r", complete)
+	if got, _ := e.edit("> "); got != "catch tentacool " {
+		t.Errorf("expected the single match to be completed, got %q", got)
+	}
+
+	e, _ = newTestEditor("catch pidg\t\r", complete)
+	if got, _ := e.edit("> "); got != "catch pidge" {
+		t.Errorf("expected the common prefix, got %q", got)
+	}
+
+	e, out := newTestEditor("catch pi\t\t\r", complete)
+	if got, _ := e.edit("> "); got != "catch pi" {
+		t.Errorf("expected the line to be unchanged, got %q", got)
+	}
+	if !strings.Contains(out.String(), "pidgeotto  pidgey  pikachu") {
+		t.Errorf("expected the second tab to list the candidates, got %q", out.String())
+	}
+}
+
+func TestEditEndings(t *testing.T) {
+	e, _ := newTestEditor("\x04", nil)
+	if _, err := e.edit("> "); err != io.EOF {
+		t.Errorf("expected io.EOF on Ctrl-D, got %v", err)
+	}
+	e, _ = newTestEditor("map\x03", nil)
+	if _, err := e.edit("> "); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected ErrInterrupted on Ctrl-C, got %v", err)
+	}
+	e, _ = newTestEditor("map", nil)
+	if got, err := e.edit("> "); err != nil || got != "map" {
+		t.Errorf("expected the unterminated line, got %q, %v", got, err)
+	}
+}
+
+func newTestHistory(entries ...string) *History {
+	h := NewHistory(0)
+	for _, entry := range entries {
+		h.Add(entry)
+	}
+	return h
+}
+
+func TestEditHistory(t *testing.T) {
+	h := newTestHistory("map", "explore canalave-city-area", "catch tentacool")
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"up", "\x1b[A\r", "catch tentacool"},
+		{"up twice", "\x1b[A\x1b[A\r", "explore canalave-city-area"},
+		{"past the oldest", "\x1b[A\x1b[A\x1b[A\x1b[A\r", "map"},
+		{"back to the draft", "ins\x1b[A\x1b[A\x1b[B\x1b[B\r", "ins"},
+		{"ctrl-p and edit", "\x10\x10 --version=diamond\r", "explore canalave-city-area --version=diamond"},
+		{"reverse search", "\x12map\r", "map"},
+		{"reverse search older", "\x12a\x12\r", "explore canalave-city-area"},
+		{"reverse search then edit", "\x12expl\x05 --version=pearl\r", "explore canalave-city-area --version=pearl"},
+		{"reverse search cancelled", "pokedex\x12catch\x07\r", "pokedex"},
+		{"reverse search backspace", "\x12catx\x7f\r", "catch tentacool"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, _ := newTestEditor(c.input, nil)
+			e.SetHistory(h)
+			got, err := e.edit("> ")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestHistoryAddAndPersist(t *testing.T) {
+	h := NewHistory(2)
+	for _, line := range []string{"map", "map", " ", "mapb", "pokedex"} {
+		h.Add(line)
+	}
+	if got := h.Entries(); len(got) != 2 || got[0] != "mapb" || got[1] != "pokedex" {
+		t.Fatalf("unexpected entries %q", got)
+	}
+
+	path := t.TempDir() + "/history"
+	if err := h.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded := NewHistory(0)
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := loaded.Entries(); len(got) != 2 || got[1] != "pokedex" {
+		t.Errorf("unexpected loaded entries %q", got)
+	}
+	if err := NewHistory(0).Load(path + ".missing"); err != nil {
+		t.Errorf("expected a missing file to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package lineedit
+
+import "errors"
+
+// rawMode is not supported here, so the Editor falls back to reading plain
+// lines.
+func rawMode(fd uintptr) (func() error, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package lineedit
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// rawMode switches the terminal on fd to raw input and returns a function
+// restoring the previous state. Output processing is left on, so "\n" still
+// starts a new line.
+func rawMode(fd uintptr) (func() error, error) {
+	var old syscall.Termios
+	if err := termios(fd, ioctlGetTermios, &old); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON | syscall.BRKINT | syscall.INPCK | syscall.ISTRIP
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := termios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return termios(fd, ioctlSetTermios, &old)
+	}, nil
+}
+
+func termios(fd uintptr, request uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
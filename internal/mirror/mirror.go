@@ -0,0 +1,486 @@
+// Package mirror downloads PokeAPI resources into a directory laid out like
+// the api-data repository, for use with pokeapi.DirSource.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+	"github.com/tholho/pokedexcli/internal/typechart"
+)
+
+// ManifestName is the file in the mirror directory recording what was
+// fetched and when.
+const ManifestName = "manifest.json"
+
+// saveEvery is how many fetches go by between manifest saves, bounding how
+// much an interrupted sync has to fetch again.
+const saveEvery = 50
+
+type Manifest struct {
+	BaseURL    string     `json:"base_url"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Resources is keyed by "<resource>/<name>", e.g. "pokemon/pikachu"
+	Resources map[string]Entry `json:"resources"`
+}
+
+type Entry struct {
+	// ID is 0 for resources without one, which are stored under their name
+	ID        int       `json:"id"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// Missing records that the API does not know the resource, so resuming
+	// doesn't ask for it again
+	Missing bool `json:"missing,omitempty"`
+}
+
+// dirName is the directory the resource is stored in, named after its id as
+// in api-data.
+func (e Entry) dirName(name string) string {
+	if e.ID == 0 {
+		return name
+	}
+	return strconv.Itoa(e.ID)
+}
+
+// Progress reports how far a phase of the sync has got.
+type Progress struct {
+	Phase string
+	Done  int
+	Total int
+}
+
+type Options struct {
+	// Workers bounds the number of concurrent requests; it defaults to 1.
+	Workers int
+	// Progress, if set, is called after every fetch, one call at a time.
+	Progress func(Progress)
+}
+
+type Result struct {
+	Fetched int
+	// Skipped counts resources already in the mirror from an earlier sync
+	Skipped int
+	// Missing counts resources referenced by others but not served by the API
+	Missing  int
+	Duration time.Duration
+}
+
+type syncer struct {
+	client   *pokeapi.Client
+	dir      string
+	opts     Options
+	mu       sync.Mutex
+	manifest Manifest
+	result   Result
+	unsaved  int
+}
+
+// Sync walks the location area list, every location area, the pokemon they
+// reference, those pokemon's species, evolution chains and level-up moves,
+// every type and every game version, writing them under dir/data/api/v2.
+// Resources recorded in dir's manifest, missing ones included, are read back
+// from disk instead of fetched again, so an interrupted sync resumes where it
+// stopped.
+func Sync(ctx context.Context, client *pokeapi.Client, dir string, opts Options) (Result, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	s := &syncer{client: client, dir: dir, opts: opts}
+	start := time.Now()
+	if err := s.loadManifest(); err != nil {
+		return s.result, err
+	}
+	err := s.run(ctx)
+	if err == nil {
+		finished := time.Now()
+		s.manifest.FinishedAt = &finished
+	}
+	// save what was fetched even on failure, so the next sync can resume
+	if saveErr := s.save(); err == nil {
+		err = saveErr
+	}
+	s.result.Duration = time.Since(start)
+	return s.result, err
+}
+
+func (s *syncer) run(ctx context.Context) error {
+	areas, err := s.listLocationAreas(ctx)
+	if err != nil {
+		return err
+	}
+	pokemon := newNameSet()
+	err = s.fetchAll(ctx, "location-area", areas, func(body []byte) error {
+		var area struct {
+			PokemonEncounters []struct {
+				Pokemon pokeapi.NamedAPIResource `json:"pokemon"`
+			} `json:"pokemon_encounters"`
+		}
+		if err := json.Unmarshal(body, &area); err != nil {
+			return err
+		}
+		for _, encounter := range area.PokemonEncounters {
+			pokemon.add(encounter.Pokemon.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	species, types, moves := newNameSet(), newNameSet(), newNameSet()
+	// weaknesses and matchups look at every attacking type, not just the
+	// ones the mirrored pokemon have
+	for _, name := range typechart.Types {
+		types.add(name)
+	}
+	err = s.fetchAll(ctx, "pokemon", pokemon.sorted(), func(body []byte) error {
+		var details struct {
+			Species pokeapi.NamedAPIResource `json:"species"`
+			Types   []struct {
+				Type pokeapi.NamedAPIResource `json:"type"`
+			} `json:"types"`
+			Moves []struct {
+				Move                pokeapi.NamedAPIResource `json:"move"`
+				VersionGroupDetails []struct {
+					MoveLearnMethod pokeapi.NamedAPIResource `json:"move_learn_method"`
+				} `json:"version_group_details"`
+			} `json:"moves"`
+		}
+		if err := json.Unmarshal(body, &details); err != nil {
+			return err
+		}
+		species.add(details.Species.Name)
+		for _, t := range details.Types {
+			types.add(t.Type.Name)
+		}
+		// battles only use the moves learned by level-up
+		for _, m := range details.Moves {
+			for _, detail := range m.VersionGroupDetails {
+				if detail.MoveLearnMethod.Name == "level-up" {
+					moves.add(m.Move.Name)
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	chains := newNameSet()
+	err = s.fetchAll(ctx, "pokemon-species", species.sorted(), func(body []byte) error {
+		var details struct {
+			EvolutionChain struct {
+				URL string `json:"url"`
+			} `json:"evolution_chain"`
+		}
+		if err := json.Unmarshal(body, &details); err != nil {
+			return err
+		}
+		// chains have no name, only the id ending their URL
+		chain := strings.TrimSuffix(details.EvolutionChain.URL, "/")
+		chains.add(chain[strings.LastIndex(chain, "/")+1:])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.fetchAll(ctx, "evolution-chain", chains.sorted(), nil); err != nil {
+		return err
+	}
+	if err := s.fetchAll(ctx, "move", moves.sorted(), nil); err != nil {
+		return err
+	}
+	if err := s.fetchAll(ctx, "type", types.sorted(), nil); err != nil {
+		return err
+	}
+	// versions back the game command and explore --version
+	versions, err := s.client.ListNames("version")
+	if err != nil {
+		return fmt.Errorf("listing versions: %w", err)
+	}
+	return s.fetchAll(ctx, "version", versions, nil)
+}
+
+// listLocationAreas follows the Next links of the location area list, the way
+// map pages through it.
+func (s *syncer) listLocationAreas(ctx context.Context) ([]string, error) {
+	var names []string
+	pageURL := s.client.LocationAreaURL("")
+	for page := 1; pageURL != ""; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		list, err := s.client.ListLocationAreas(pageURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range list.Results {
+			names = append(names, result.Name)
+		}
+		pages := page
+		if len(list.Results) > 0 {
+			pages = (list.Count + len(list.Results) - 1) / len(list.Results)
+		}
+		s.report(Progress{Phase: "location-area list", Done: page, Total: max(page, pages)})
+		pageURL = list.Next
+	}
+	return names, nil
+}
+
+// fetchAll stores every named resource, passing each body to visit. It stops
+// at the first error other than the API not knowing a resource.
+func (s *syncer) fetchAll(ctx context.Context, resource string, names []string, visit func([]byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	work := make(chan string)
+	var wg sync.WaitGroup
+	var firstErr error
+	var errOnce sync.Once
+	done := 0
+	for range s.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range work {
+				body, err := s.fetch(ctx, resource, name)
+				if err == nil && body != nil && visit != nil {
+					err = visit(body)
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("syncing %s %s: %w", resource, name, err)
+						cancel()
+					})
+					continue
+				}
+				// report under the lock so calls never overlap or go backwards
+				s.mu.Lock()
+				done++
+				s.report(Progress{Phase: resource, Done: done, Total: len(names)})
+				s.mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, name := range names {
+		select {
+		case work <- name:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	// the parent context, not our own cancel, stopped the feed
+	return ctx.Err()
+}
+
+// fetch returns the body of one resource, from the mirror if an earlier sync
+// stored it, else from the API. A resource the API does not know is counted
+// as missing, recorded as such in the manifest, and has a nil body.
+func (s *syncer) fetch(ctx context.Context, resource, name string) ([]byte, error) {
+	key := resource + "/" + name
+	s.mu.Lock()
+	entry, ok := s.manifest.Resources[key]
+	if ok && entry.Missing {
+		s.result.Missing++
+		s.mu.Unlock()
+		return nil, nil
+	}
+	s.mu.Unlock()
+	if ok {
+		body, err := os.ReadFile(s.path(resource, entry.dirName(name)))
+		if err == nil {
+			s.mu.Lock()
+			s.result.Skipped++
+			s.mu.Unlock()
+			return body, nil
+		}
+	}
+
+	body, err := s.client.GetRaw(ctx, s.client.BaseURL()+"/"+resource+"/"+name+"/")
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, s.record(key, Entry{FetchedAt: time.Now(), Missing: true})
+	}
+	if err != nil {
+		return nil, err
+	}
+	// api-data uses URLs relative to the host, which DirSource expects
+	body = bytes.ReplaceAll(body, []byte(s.client.BaseURL()+"/"), []byte(pokeapi.OfflineBaseURL+"/"))
+	var ident struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(body, &ident); err != nil {
+		return nil, err
+	}
+	entry = Entry{ID: ident.ID, FetchedAt: time.Now()}
+	if err := writeFile(s.path(resource, entry.dirName(name)), body); err != nil {
+		return nil, err
+	}
+	return body, s.record(key, entry)
+}
+
+// record adds a fetched or missing resource to the manifest, saving it every
+// saveEvery records.
+func (s *syncer) record(key string, entry Entry) error {
+	s.mu.Lock()
+	s.manifest.Resources[key] = entry
+	if entry.Missing {
+		s.result.Missing++
+	} else {
+		s.result.Fetched++
+	}
+	s.unsaved++
+	save := s.unsaved >= saveEvery
+	s.mu.Unlock()
+	if save {
+		return s.save()
+	}
+	return nil
+}
+
+func (s *syncer) report(progress Progress) {
+	if s.opts.Progress != nil {
+		s.opts.Progress(progress)
+	}
+}
+
+func (s *syncer) path(resource, id string) string {
+	return filepath.Join(s.dir, "data", "api", "v2", resource, id, "index.json")
+}
+
+func (s *syncer) loadManifest() error {
+	s.manifest = Manifest{
+		BaseURL:   s.client.BaseURL(),
+		StartedAt: time.Now(),
+		Resources: map[string]Entry{},
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var previous Manifest
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return fmt.Errorf("reading %s: %w", ManifestName, err)
+	}
+	// resources from another API may not match, so fetch them all again
+	if previous.BaseURL == s.manifest.BaseURL && previous.Resources != nil {
+		s.manifest.Resources = previous.Resources
+	}
+	return nil
+}
+
+// save writes the manifest, then the index.json list of each resource that
+// DirSource resolves names with.
+func (s *syncer) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unsaved = 0
+	lists := map[string][]pokeapi.NamedAPIResource{}
+	ids := map[string]int{}
+	for key, entry := range s.manifest.Resources {
+		if entry.Missing {
+			continue
+		}
+		resource, name, _ := strings.Cut(key, "/")
+		url := fmt.Sprintf("%s/%s/%s/", pokeapi.OfflineBaseURL, resource, entry.dirName(name))
+		lists[resource] = append(lists[resource], pokeapi.NamedAPIResource{Name: name, URL: url})
+		ids[url] = entry.ID
+	}
+	for resource, results := range lists {
+		sort.Slice(results, func(i, j int) bool {
+			return ids[results[i].URL] < ids[results[j].URL]
+		})
+		data, err := json.Marshal(map[string]any{
+			"count":    len(results),
+			"next":     nil,
+			"previous": nil,
+			"results":  results,
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeFile(s.path(resource, ""), data); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(s.dir, ManifestName), data)
+}
+
+// writeFile writes through a temp file so an interrupted sync never leaves a
+// half-written file behind.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+type nameSet struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func newNameSet() *nameSet {
+	return &nameSet{names: map[string]bool{}}
+}
+
+func (n *nameSet) add(name string) {
+	if name == "" {
+		return
+	}
+	n.mu.Lock()
+	n.names[name] = true
+	n.mu.Unlock()
+}
+
+func (n *nameSet) sorted() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	names := make([]string, 0, len(n.names))
+	for name := range n.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
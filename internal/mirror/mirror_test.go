@@ -0,0 +1,113 @@
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+	"github.com/tholho/pokedexcli/internal/typechart"
+)
+
+func newFakeAPI(t *testing.T, requests map[string]int, mu *sync.Mutex) *httptest.Server {
+	var server *httptest.Server
+	bodies := map[string]string{
+		"/location-area/": `{"count":1,"next":null,"results":[{"name":"canalave-city-area","url":"{base}/location-area/1/"}]}`,
+		"/location-area/canalave-city-area/": `{"id":1,"name":"canalave-city-area","pokemon_encounters":[
+			{"pokemon":{"name":"tentacool","url":"{base}/pokemon/72/"}},
+			{"pokemon":{"name":"missingno","url":"{base}/pokemon/0/"}}]}`,
+		"/pokemon/tentacool/": `{"id":72,"name":"tentacool","species":{"name":"tentacool","url":"{base}/pokemon-species/72/"},"types":[{"type":{"name":"water","url":"{base}/type/11/"}}],
+			"moves":[{"move":{"name":"poison-sting"},"version_group_details":[{"move_learn_method":{"name":"level-up"}}]},
+				{"move":{"name":"surf"},"version_group_details":[{"move_learn_method":{"name":"machine"}}]}]}`,
+		"/pokemon-species/tentacool/": `{"id":72,"name":"tentacool","evolution_chain":{"url":"{base}/evolution-chain/30/"}}`,
+		"/evolution-chain/30/":        `{"id":30}`,
+		"/type/water/":                `{"id":11,"name":"water"}`,
+		"/move/poison-sting/":         `{"id":40,"name":"poison-sting","power":15}`,
+		"/version/":                   `{"count":2,"next":null,"results":[{"name":"diamond","url":"{base}/version/12/"},{"name":"pearl","url":"{base}/version/13/"}]}`,
+		"/version/diamond/":           `{"id":12,"name":"diamond"}`,
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(strings.ReplaceAll(body, "{base}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSyncWritesMirrorAndResumes(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := newFakeAPI(t, requests, &mu)
+	client := pokeapi.NewClient(server.URL, server.Client(), nil)
+	dir := t.TempDir()
+
+	var last Progress
+	res, err := Sync(context.Background(), client, dir, Options{Workers: 2, Progress: func(p Progress) { last = p }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// missingno, pearl and every attacking type but water are missing from
+	// the fake API
+	if res.Fetched != 7 || res.Missing != len(typechart.Types)+1 || res.Skipped != 0 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if last.Phase != "version" || last.Done != 2 {
+		t.Errorf("unexpected last progress %+v", last)
+	}
+
+	source, err := pokeapi.NewDirSource(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	offline := pokeapi.NewClientWithSource(pokeapi.OfflineBaseURL, source, nil)
+	pokemon, err := offline.GetPokemon("tentacool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.Species.URL != "/api/v2/pokemon-species/72/" {
+		t.Errorf("expected URLs relative to the host, got %q", pokemon.Species.URL)
+	}
+	if _, err := offline.GetType("water"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if move, err := offline.GetMove("poison-sting"); err != nil || move.Power != 15 {
+		t.Errorf("expected poison-sting to be mirrored, got %+v, %v", move, err)
+	}
+	if versions, err := offline.ListNames("version"); err != nil || len(versions) != 1 || versions[0] != "diamond" {
+		t.Errorf("expected only diamond to be listed, got %v, %v", versions, err)
+	}
+
+	res, err = Sync(context.Background(), client, dir, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Fetched != 0 || res.Skipped != 7 || res.Missing != len(typechart.Types)+1 {
+		t.Errorf("expected the second sync to reuse the mirror, got %+v", res)
+	}
+	for _, path := range []string{"/pokemon/tentacool/", "/pokemon/missingno/", "/type/fire/", "/version/pearl/"} {
+		if n := requests[path]; n != 1 {
+			t.Errorf("expected %s to be requested once, got %d", path, n)
+		}
+	}
+}
+
+func TestSyncStopsOnContext(t *testing.T) {
+	var mu sync.Mutex
+	server := newFakeAPI(t, map[string]int{}, &mu)
+	client := pokeapi.NewClient(server.URL, server.Client(), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Sync(ctx, client, t.TempDir(), Options{}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package pokeapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+const DefaultBaseURL = "https://pokeapi.co/api/v2"
+
+// Client fetches PokeAPI resources, going through the cache first.
+type Client struct {
+	baseURL string
+	source  Source
+	cache   *pokecache.Cache
+}
+
+// NewClient returns a Client for the API rooted at baseURL. A nil httpClient
+// falls back to one using a Transport with default options and
+// DefaultMaxRetries.
+func NewClient(baseURL string, httpClient *http.Client, cache *pokecache.Cache) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: NewTransport(nil, TransportOptions{MaxRetries: DefaultMaxRetries})}
+	}
+	return NewClientWithSource(baseURL, httpSource{client: httpClient}, cache)
+}
+
+// NewClientWithSource returns a Client that loads the resources under baseURL
+// from source, e.g. a DirSource for offline use.
+func NewClientWithSource(baseURL string, source Source, cache *pokecache.Cache) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		source:  source,
+		cache:   cache,
+	}
+}
+
+func (c *Client) endpoint(resource, name string) string {
+	return c.baseURL + "/" + resource + "/" + name
+}
+
+// get returns the body at url from the cache, fetching it on a miss.
+// Concurrent misses on the same url share one request, and expired entries
+// are revalidated with a conditional request when the server sent validators.
+func (c *Client) get(url string) ([]byte, error) {
+	if c.cache == nil {
+		body, _, err := c.source.Fetch(context.Background(), url, pokecache.Validators{})
+		return body, err
+	}
+	return c.cache.GetOrRevalidate(url, func(stale pokecache.Validators) ([]byte, pokecache.Validators, error) {
+		return c.source.Fetch(context.Background(), url, stale)
+	})
+}
+
+// BaseURL is the root the Client builds resource URLs from.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// GetRaw fetches the body at url without going through the cache, for bulk
+// downloads that would otherwise evict everything else from it.
+func (c *Client) GetRaw(ctx context.Context, url string) ([]byte, error) {
+	body, _, err := c.source.Fetch(ctx, url, pokecache.Validators{})
+	return body, err
+}
+
+// prefetch fills the cache with the body at url unless it already holds it.
+// Unlike get it doesn't share its request with concurrent lookups, so
+// cancelling ctx only abandons this fetch, never one a lookup waits on.
+func (c *Client) prefetch(ctx context.Context, url string) error {
+	if c.cache == nil || c.cache.Contains(url) {
+		return nil
+	}
+	body, validators, err := c.source.Fetch(ctx, url, pokecache.Validators{})
+	if err != nil {
+		return err
+	}
+	return c.cache.AddValidated(url, body, validators)
+}
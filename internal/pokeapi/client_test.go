@@ -0,0 +1,130 @@
+package pokeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+func TestGetPokemonUsesCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/pokemon/pikachu" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"name":"pikachu","base_experience":112}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), pokecache.NewCache(time.Minute))
+	for i := 0; i < 2; i++ {
+		pokemon, err := client.GetPokemon("pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+			t.Errorf("unexpected pokemon %+v", pokemon.Name)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestPrefetchPokemon(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), pokecache.NewCache(time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.PrefetchPokemon(ctx, "pikachu"); err == nil {
+		t.Errorf("expected a cancelled prefetch to fail")
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.PrefetchPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestListLocationAreasFirstPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/location-area/" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"count":2,"next":"next-page","results":[{"name":"canalave-city-area"},{"name":"eterna-city-area"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", server.Client(), nil)
+	page, err := client.ListLocationAreas("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Results) != 2 || page.Next != "next-page" {
+		t.Errorf("unexpected page %+v", page)
+	}
+}
+
+func TestGetRevalidatesExpiredEntries(t *testing.T) {
+	for _, withDisk := range []bool{true, false} {
+		var full, notModified int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			full++
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"name":"pikachu","base_experience":112}`))
+		}))
+		defer server.Close()
+
+		var opts []pokecache.Option
+		if withDisk {
+			disk, err := pokecache.NewDiskStore(t.TempDir(), 20*time.Millisecond, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			opts = append(opts, pokecache.WithDiskStore(disk))
+		}
+		cache := pokecache.NewCache(10*time.Millisecond, opts...)
+		defer cache.Close()
+		client := NewClient(server.URL, server.Client(), cache)
+
+		for i := 0; i < 2; i++ {
+			pokemon, err := client.GetPokemon("pikachu")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pokemon.Name != "pikachu" {
+				t.Errorf("unexpected pokemon %+v", pokemon.Name)
+			}
+			// let both the memory and the disk entry expire
+			time.Sleep(50 * time.Millisecond)
+		}
+		if full != 1 || notModified != 1 {
+			t.Errorf("disk %v: expected 1 full and 1 conditional request, got %d and %d", withDisk, full, notModified)
+		}
+		if stats := cache.Stats(); stats.Revalidations != 1 {
+			t.Errorf("disk %v: expected 1 revalidation, got %d", withDisk, stats.Revalidations)
+		}
+	}
+}
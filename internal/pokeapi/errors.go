@@ -0,0 +1,57 @@
+package pokeapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("resource not found")
+	ErrRateLimited = errors.New("rate limited by the API")
+	ErrUpstream    = errors.New("API error")
+)
+
+// HTTPError is returned for responses other than 200 OK. It matches
+// ErrNotFound, ErrRateLimited or ErrUpstream with errors.Is.
+type HTTPError struct {
+	URL        string
+	StatusCode int
+	// RetryAfter is the delay asked for by a 429 or 503, if any.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return ErrUpstream
+	}
+}
+
+// MirrorError is returned by a DirSource for resources missing from the
+// mirror. It matches ErrNotFound with errors.Is.
+type MirrorError struct {
+	Resource string
+	Name     string
+	Dir      string
+}
+
+func (e *MirrorError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("the %s list is not in the offline mirror at %s", e.Resource, e.Dir)
+	}
+	return fmt.Sprintf("%s %q is not in the offline mirror at %s", e.Resource, e.Name, e.Dir)
+}
+
+func (e *MirrorError) Unwrap() error {
+	return ErrNotFound
+}
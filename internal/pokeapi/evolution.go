@@ -0,0 +1,25 @@
+package pokeapi
+
+import "encoding/json"
+
+func (c *Client) GetPokemonSpecies(name string) (PokemonSpeciesAPIResponse, error) {
+	var jsonData PokemonSpeciesAPIResponse
+	data, err := c.get(c.endpoint("pokemon-species", name))
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
+
+// GetEvolutionChain takes the chain URL found in a species, since chains are
+// only addressed by id.
+func (c *Client) GetEvolutionChain(chainURL string) (EvolutionChainAPIResponse, error) {
+	var jsonData EvolutionChainAPIResponse
+	data, err := c.get(chainURL)
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
@@ -0,0 +1,34 @@
+package pokeapi
+
+import "encoding/json"
+
+// LocationAreaURL returns the URL of a location area, or of the first page of
+// the location area list when name is empty.
+func (c *Client) LocationAreaURL(name string) string {
+	return c.endpoint("location-area", name)
+}
+
+// ListLocationAreas fetches one page of the location area list. An empty
+// pageURL means the first page.
+func (c *Client) ListLocationAreas(pageURL string) (LocationAreaAPIResponse, error) {
+	var jsonData LocationAreaAPIResponse
+	if pageURL == "" {
+		pageURL = c.LocationAreaURL("")
+	}
+	data, err := c.get(pageURL)
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
+
+func (c *Client) GetLocationArea(name string) (LocationAPIResponse, error) {
+	var jsonData LocationAPIResponse
+	data, err := c.get(c.LocationAreaURL(name))
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
@@ -0,0 +1,13 @@
+package pokeapi
+
+import "encoding/json"
+
+func (c *Client) GetMove(name string) (MoveAPIResponse, error) {
+	var jsonData MoveAPIResponse
+	data, err := c.get(c.endpoint("move", name))
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
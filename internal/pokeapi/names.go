@@ -0,0 +1,29 @@
+package pokeapi
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// listAll is a page size large enough to get every resource of a kind at once.
+const listAll = 100000
+
+// ListNames returns the names of every resource of a kind, e.g. "pokemon",
+// fetching the whole list in one request.
+func (c *Client) ListNames(resource string) ([]string, error) {
+	var jsonData struct {
+		Results []NamedAPIResource `json:"results"`
+	}
+	data, err := c.get(c.endpoint(resource, "") + "?offset=0&limit=" + strconv.Itoa(listAll))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &jsonData); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(jsonData.Results))
+	for i, result := range jsonData.Results {
+		names[i] = result.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,169 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+// OfflineBaseURL is the base URL to give NewClientWithSource along with a
+// DirSource. It matches the relative URLs found inside the mirrored files.
+const OfflineBaseURL = "/api/v2"
+
+// defaultPageSize is the page size PokeAPI uses when none is asked for.
+const defaultPageSize = 20
+
+// DirSource serves resources from a local directory laid out like the
+// PokeAPI api-data repository, where /api/v2/pokemon/25/ lives in
+// api/v2/pokemon/25/index.json. Resources can be asked for by id or by name;
+// names are looked up in the resource's own index.json.
+type DirSource struct {
+	dir string
+	mu  sync.Mutex
+	// ids maps each resource to its name -> id index, loaded on first use
+	ids map[string]map[string]string
+}
+
+type resourceList struct {
+	Count    int                `json:"count"`
+	Next     *string            `json:"next"`
+	Previous *string            `json:"previous"`
+	Results  []NamedAPIResource `json:"results"`
+}
+
+// NewDirSource serves the mirror at dir, which holds api/v2 either directly
+// or under data/, as in a checkout of the api-data repository.
+func NewDirSource(dir string) (*DirSource, error) {
+	for _, root := range []string{filepath.Join(dir, "data"), dir} {
+		info, err := os.Stat(filepath.Join(root, "api", "v2"))
+		if err == nil && info.IsDir() {
+			return &DirSource{dir: root, ids: map[string]map[string]string{}}, nil
+		}
+	}
+	return nil, fmt.Errorf("no PokeAPI mirror in %s: expected an api/v2 directory", dir)
+}
+
+// Dir is the directory api/v2 was found in.
+func (s *DirSource) Dir() string {
+	return s.dir
+}
+
+func (s *DirSource) Fetch(_ context.Context, rawURL string, _ pokecache.Validators) ([]byte, pokecache.Validators, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, pokecache.Validators{}, err
+	}
+	path, ok := strings.CutPrefix(u.Path, OfflineBaseURL+"/")
+	if !ok {
+		return nil, pokecache.Validators{}, fmt.Errorf("%s is not a PokeAPI v2 URL", rawURL)
+	}
+	resource, name, _ := strings.Cut(strings.Trim(path, "/"), "/")
+	var body []byte
+	if name == "" {
+		body, err = s.page(resource, u.Query())
+	} else {
+		body, err = s.resource(resource, name)
+	}
+	return body, pokecache.Validators{}, err
+}
+
+func (s *DirSource) file(resource, id string) string {
+	return filepath.Join(s.dir, "api", "v2", resource, id, "index.json")
+}
+
+// resource reads a single resource, by id or by name.
+func (s *DirSource) resource(resource, name string) ([]byte, error) {
+	id := name
+	if _, err := strconv.Atoi(name); err != nil {
+		ids, err := s.index(resource)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if id, ok = ids[name]; !ok {
+			return nil, &MirrorError{Resource: resource, Name: name, Dir: s.dir}
+		}
+	}
+	body, err := os.ReadFile(s.file(resource, id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &MirrorError{Resource: resource, Name: name, Dir: s.dir}
+	}
+	return body, err
+}
+
+// index returns the name -> id index of resource. Callers must not hold s.mu.
+func (s *DirSource) index(resource string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ids, ok := s.ids[resource]; ok {
+		return ids, nil
+	}
+	list, err := s.list(resource)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(list.Results))
+	for _, result := range list.Results {
+		ids[result.Name] = lastSegment(result.URL)
+	}
+	s.ids[resource] = ids
+	return ids, nil
+}
+
+func (s *DirSource) list(resource string) (resourceList, error) {
+	var list resourceList
+	data, err := os.ReadFile(s.file(resource, ""))
+	if errors.Is(err, fs.ErrNotExist) {
+		return list, &MirrorError{Resource: resource, Dir: s.dir}
+	}
+	if err != nil {
+		return list, err
+	}
+	err = json.Unmarshal(data, &list)
+	return list, err
+}
+
+// page cuts one page out of the full list kept in the resource's index.json,
+// honouring offset and limit like the live API does.
+func (s *DirSource) page(resource string, query url.Values) ([]byte, error) {
+	list, err := s.list(resource)
+	if err != nil {
+		return nil, err
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageSize
+	}
+	offset = min(max(offset, 0), len(list.Results))
+	end := min(offset+limit, len(list.Results))
+	pageURL := func(offset int) *string {
+		u := fmt.Sprintf("%s/%s/?offset=%d&limit=%d", OfflineBaseURL, resource, offset, limit)
+		return &u
+	}
+	page := resourceList{Count: len(list.Results), Results: list.Results[offset:end]}
+	if end < len(list.Results) {
+		page.Next = pageURL(end)
+	}
+	if offset > 0 {
+		page.Previous = pageURL(max(offset-limit, 0))
+	}
+	return json.Marshal(page)
+}
+
+// lastSegment returns the id at the end of a resource URL like
+// /api/v2/pokemon/25/.
+func lastSegment(resourceURL string) string {
+	trimmed := strings.TrimSuffix(resourceURL, "/")
+	return trimmed[strings.LastIndex(trimmed, "/")+1:]
+}
@@ -0,0 +1,102 @@
+package pokeapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeMirrorFile(t *testing.T, dir, path, body string) {
+	t.Helper()
+	file := filepath.Join(dir, "data", "api", "v2", path, "index.json")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newMirror(t *testing.T) *Client {
+	t.Helper()
+	dir := t.TempDir()
+	writeMirrorFile(t, dir, "pokemon", `{"count":1,"next":null,"previous":null,"results":[{"name":"pikachu","url":"/api/v2/pokemon/25/"}]}`)
+	writeMirrorFile(t, dir, "pokemon/25", `{"name":"pikachu","base_experience":112}`)
+	var areas []string
+	for i := 1; i <= 25; i++ {
+		areas = append(areas, fmt.Sprintf(`{"name":"area-%d","url":"/api/v2/location-area/%d/"}`, i, i))
+	}
+	writeMirrorFile(t, dir, "location-area", `{"count":25,"next":null,"previous":null,"results":[`+strings.Join(areas, ",")+`]}`)
+
+	source, err := NewDirSource(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return NewClientWithSource(OfflineBaseURL, source, nil)
+}
+
+func TestDirSourceGetPokemon(t *testing.T) {
+	client := newMirror(t)
+	for _, name := range []string{"pikachu", "25"} {
+		pokemon, err := client.GetPokemon(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+			t.Errorf("unexpected pokemon %+v", pokemon.Name)
+		}
+	}
+
+	_, err := client.GetPokemon("mew")
+	var mirrorErr *MirrorError
+	if !errors.As(err, &mirrorErr) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a MirrorError matching ErrNotFound, got %v", err)
+	}
+	if mirrorErr.Resource != "pokemon" || mirrorErr.Name != "mew" {
+		t.Errorf("unexpected error %+v", mirrorErr)
+	}
+
+	if _, err := client.GetMove("tackle"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a resource missing from the mirror, got %v", err)
+	}
+}
+
+func TestDirSourcePagesLists(t *testing.T) {
+	client := newMirror(t)
+	first, err := client.ListLocationAreas("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Results) != 20 || first.Results[0].Name != "area-1" || first.Previous != "" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	second, err := client.ListLocationAreas(first.Next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Results) != 5 || second.Results[0].Name != "area-21" || second.Next != "" {
+		t.Errorf("unexpected second page %+v", second)
+	}
+	if second.Previous != "/api/v2/location-area/?offset=0&limit=20" {
+		t.Errorf("unexpected previous page %q", second.Previous)
+	}
+}
+
+func TestNewDirSourceNeedsMirror(t *testing.T) {
+	if _, err := NewDirSource(t.TempDir()); err == nil {
+		t.Errorf("expected an error for a directory without api/v2")
+	}
+}
+
+func TestDirSourceListNames(t *testing.T) {
+	names, err := newMirror(t).ListNames("location-area")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(names) != 25 || names[24] != "area-25" {
+		t.Errorf("expected every area in one list, got %d names", len(names))
+	}
+}
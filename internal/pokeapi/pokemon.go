@@ -0,0 +1,28 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+func (c *Client) GetPokemon(name string) (PokemonAPIResponse, error) {
+	var jsonData PokemonAPIResponse
+	data, err := c.get(c.endpoint("pokemon", name))
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
+
+// PrefetchPokemon caches a pokemon ahead of a GetPokemon. Cancelling ctx
+// abandons the request, which is never shared with a GetPokemon waiting on
+// the same pokemon.
+func (c *Client) PrefetchPokemon(ctx context.Context, name string) error {
+	return c.prefetch(ctx, c.endpoint("pokemon", name))
+}
+
+// GetSprite downloads a sprite image, as found in a pokemon's Sprites.
+func (c *Client) GetSprite(url string) ([]byte, error) {
+	return c.get(url)
+}
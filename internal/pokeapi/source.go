@@ -0,0 +1,60 @@
+package pokeapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/tholho/pokedexcli/internal/pokecache"
+)
+
+// Source loads the raw body of a PokeAPI resource URL. stale holds the
+// validators of an expired cached copy, if any; a Source that supports
+// conditional requests answers pokecache.ErrNotModified when it is current.
+// Cancelling ctx abandons the fetch.
+type Source interface {
+	Fetch(ctx context.Context, url string, stale pokecache.Validators) ([]byte, pokecache.Validators, error)
+}
+
+// httpSource fetches resources over HTTP.
+type httpSource struct {
+	client *http.Client
+}
+
+func (s httpSource) Fetch(ctx context.Context, url string, stale pokecache.Validators) ([]byte, pokecache.Validators, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, pokecache.Validators{}, err
+	}
+	if stale.ETag != "" {
+		req.Header.Set("If-None-Match", stale.ETag)
+	}
+	if stale.LastModified != "" {
+		req.Header.Set("If-Modified-Since", stale.LastModified)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, pokecache.Validators{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && !stale.IsZero() {
+		drain(res)
+		return nil, pokecache.Validators{}, pokecache.ErrNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		drain(res)
+		return nil, pokecache.Validators{}, &HTTPError{
+			URL:        url,
+			StatusCode: res.StatusCode,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+		}
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, pokecache.Validators{}, err
+	}
+	return body, pokecache.Validators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}, nil
+}
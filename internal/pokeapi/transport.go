@@ -0,0 +1,185 @@
+package pokeapi
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRetries is the number of retries callers should ask for when the
+// user hasn't picked one.
+const DefaultMaxRetries = 3
+
+// TransportOptions tunes the transport built by NewTransport. Zero values
+// pick the defaults noted on each field, except for MaxRetries.
+type TransportOptions struct {
+	// Timeout bounds each attempt, including reading the body. Default 10s.
+	Timeout time.Duration
+	// MaxRetries is the number of retries after the first attempt on
+	// network errors, 429 and 5xx responses. Zero or negative disables them.
+	MaxRetries int
+	// BaseBackoff is doubled on each retry, with full jitter. Default 250ms.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, Retry-After included.
+	// Default 30s.
+	MaxBackoff time.Duration
+	// RequestsPerSecond and Burst size the client-side token bucket.
+	// A zero rate disables it. Burst defaults to 1.
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// Transport is an http.RoundTripper adding timeouts, retries with backoff
+// and client-side rate limiting to GET requests.
+type Transport struct {
+	base    http.RoundTripper
+	opts    TransportOptions
+	limiter *tokenBucket
+}
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil.
+func NewTransport(base http.RoundTripper, opts TransportOptions) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.BaseBackoff == 0 {
+		opts.BaseBackoff = 250 * time.Millisecond
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+	t := &Transport{base: base, opts: opts}
+	if opts.RequestsPerSecond > 0 {
+		t.limiter = newTokenBucket(opts.RequestsPerSecond, max(opts.Burst, 1))
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		res, err := t.attempt(req)
+		if !retryable || attempt >= t.opts.MaxRetries || !shouldRetry(res, err) || req.Context().Err() != nil {
+			return res, err
+		}
+		delay := t.backoff(attempt)
+		if res != nil {
+			if retryAfter := parseRetryAfter(res.Header.Get("Retry-After")); retryAfter > 0 {
+				delay = min(retryAfter, t.opts.MaxBackoff)
+			}
+			drain(res)
+			res.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends req once under the per-attempt timeout, which stays active
+// until the response body is closed.
+func (t *Transport) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.opts.Timeout)
+	res, err := t.base.RoundTrip(req.Clone(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+// backoff is a random delay up to BaseBackoff*2^attempt, capped by MaxBackoff.
+func (t *Transport) backoff(attempt int) time.Duration {
+	ceiling := t.opts.BaseBackoff << attempt
+	if ceiling <= 0 || ceiling > t.opts.MaxBackoff {
+		ceiling = t.opts.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// drain reads what is left of a response body that won't be used, so the
+// connection can be reused.
+func drain(res *http.Response) {
+	io.Copy(io.Discard, res.Body)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// tokenBucket allows burst requests at once, refilled at rate per second.
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	burst  float64
+	rate   float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{tokens: float64(burst), burst: float64(burst), rate: rate, last: time.Now()}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
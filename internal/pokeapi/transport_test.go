@@ -0,0 +1,127 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient(server *httptest.Server, opts TransportOptions) *Client {
+	if opts.BaseBackoff == 0 {
+		opts.BaseBackoff = time.Millisecond
+	}
+	httpClient := &http.Client{Transport: NewTransport(server.Client().Transport, opts)}
+	return NewClient(server.URL, httpClient, nil)
+}
+
+func TestTransportRetriesUpstreamErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"name":"pikachu"}`))
+		}
+	}))
+	defer server.Close()
+
+	pokemon, err := testClient(server, TransportOptions{MaxRetries: DefaultMaxRetries}).GetPokemon("pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.Name != "pikachu" || requests.Load() != 3 {
+		t.Errorf("expected pikachu after 3 requests, got %q after %d", pokemon.Name, requests.Load())
+	}
+}
+
+func TestTransportGivesUp(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := testClient(server, TransportOptions{MaxRetries: 2}).GetPokemon("pikachu")
+	if !errors.Is(err, ErrUpstream) {
+		t.Errorf("expected an upstream error, got %v", err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestTransportZeroRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := testClient(server, TransportOptions{MaxRetries: 0}).GetPokemon("pikachu")
+	if !errors.Is(err, ErrUpstream) {
+		t.Errorf("expected an upstream error, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected a single request, got %d", requests.Load())
+	}
+}
+
+func TestNotFoundIsNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := testClient(server, TransportOptions{}).GetPokemon("pikachuu")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestTransportTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := testClient(server, TransportOptions{Timeout: 20 * time.Millisecond}).GetPokemon("pikachu")
+	if err == nil {
+		t.Errorf("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the request to time out quickly, took %s", elapsed)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	bucket := newTokenBucket(100, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := bucket.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the burst covers two requests, the other two wait ~10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected the limiter to delay requests, took %s", elapsed)
+	}
+}
@@ -0,0 +1,13 @@
+package pokeapi
+
+import "encoding/json"
+
+func (c *Client) GetType(name string) (TypeAPIResponse, error) {
+	var jsonData TypeAPIResponse
+	data, err := c.get(c.endpoint("type", name))
+	if err != nil {
+		return jsonData, err
+	}
+	err = json.Unmarshal(data, &jsonData)
+	return jsonData, err
+}
@@ -0,0 +1,52 @@
+package pokeapi
+
+type PokemonSpeciesAPIResponse struct {
+	ID                 int              `json:"id"`
+	Name               string           `json:"name"`
+	BaseHappiness      int              `json:"base_happiness"`
+	CaptureRate        int              `json:"capture_rate"`
+	EvolvesFromSpecies NamedAPIResource `json:"evolves_from_species"`
+	EvolutionChain     struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	Varieties []struct {
+		IsDefault bool             `json:"is_default"`
+		Pokemon   NamedAPIResource `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+type EvolutionChainAPIResponse struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+type ChainLink struct {
+	IsBaby           bool              `json:"is_baby"`
+	Species          NamedAPIResource  `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+// EvolutionDetail describes one way to evolve. Fields that don't apply are
+// null in the API and decode to their zero value, except the ones where 0 is
+// a meaningful condition, which are pointers left nil.
+type EvolutionDetail struct {
+	Trigger               NamedAPIResource `json:"trigger"`
+	MinLevel              int              `json:"min_level"`
+	Item                  NamedAPIResource `json:"item"`
+	HeldItem              NamedAPIResource `json:"held_item"`
+	KnownMove             NamedAPIResource `json:"known_move"`
+	KnownMoveType         NamedAPIResource `json:"known_move_type"`
+	Location              NamedAPIResource `json:"location"`
+	TradeSpecies          NamedAPIResource `json:"trade_species"`
+	PartySpecies          NamedAPIResource `json:"party_species"`
+	PartyType             NamedAPIResource `json:"party_type"`
+	MinHappiness          int              `json:"min_happiness"`
+	MinBeauty             int              `json:"min_beauty"`
+	MinAffection          int              `json:"min_affection"`
+	Gender                *int             `json:"gender"`
+	RelativePhysicalStats *int             `json:"relative_physical_stats"`
+	TimeOfDay             string           `json:"time_of_day"`
+	NeedsOverworldRain    bool             `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool             `json:"turn_upside_down"`
+}
@@ -0,0 +1,68 @@
+package pokeapi
+
+type LocationAreaAPIResponse struct {
+	Count    int    `json:"count"`
+	Next     string `json:"next"`
+	Previous string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
+type LocationAPIResponse struct {
+	EncounterMethodRates []struct {
+		EncounterMethod struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"encounter_method"`
+		VersionDetails []struct {
+			Rate    int `json:"rate"`
+			Version struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			} `json:"version"`
+		} `json:"version_details"`
+	} `json:"encounter_method_rates"`
+	GameIndex int `json:"game_index"`
+	ID        int `json:"id"`
+	Location  struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"location"`
+	Name  string `json:"name"`
+	Names []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+	PokemonEncounters []struct {
+		Pokemon struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+		VersionDetails []VersionEncounterDetail `json:"version_details"`
+	} `json:"pokemon_encounters"`
+}
+
+// VersionEncounterDetail lists how a pokemon is encountered in one game
+// version.
+type VersionEncounterDetail struct {
+	EncounterDetails []struct {
+		Chance          int   `json:"chance"`
+		ConditionValues []any `json:"condition_values"`
+		MaxLevel        int   `json:"max_level"`
+		Method          struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"method"`
+		MinLevel int `json:"min_level"`
+	} `json:"encounter_details"`
+	MaxChance int `json:"max_chance"`
+	Version   struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"version"`
+}
@@ -0,0 +1,18 @@
+package pokeapi
+
+type MoveAPIResponse struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Accuracy int    `json:"accuracy"`
+	Power    int    `json:"power"`
+	PP       int    `json:"pp"`
+	Priority int    `json:"priority"`
+	Type     struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"type"`
+	DamageClass struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"damage_class"`
+}
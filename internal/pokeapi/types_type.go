@@ -0,0 +1,19 @@
+package pokeapi
+
+type TypeAPIResponse struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	DamageRelations struct {
+		DoubleDamageFrom []NamedAPIResource `json:"double_damage_from"`
+		DoubleDamageTo   []NamedAPIResource `json:"double_damage_to"`
+		HalfDamageFrom   []NamedAPIResource `json:"half_damage_from"`
+		HalfDamageTo     []NamedAPIResource `json:"half_damage_to"`
+		NoDamageFrom     []NamedAPIResource `json:"no_damage_from"`
+		NoDamageTo       []NamedAPIResource `json:"no_damage_to"`
+	} `json:"damage_relations"`
+}
+
+type NamedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
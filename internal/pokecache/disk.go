@@ -0,0 +1,219 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskStore keeps cache entries as one file per key in a directory, named
+// after the SHA-256 of the key. Entries older than ttl are ignored and the
+// directory is trimmed, oldest first, to stay under maxBytes. Expired entries
+// that carry validators are kept, so they can be revalidated with the server
+// rather than downloaded again; their files end in revalidatableSuffix so
+// trimming can tell them apart without reading them.
+type DiskStore struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+const revalidatableSuffix = ".rv"
+
+type diskEntry struct {
+	Key          string    `json:"key"`
+	CreatedAt    time.Time `json:"created_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Val          []byte    `json:"val"`
+}
+
+func (e diskEntry) validators() Validators {
+	return Validators{ETag: e.ETag, LastModified: e.LastModified}
+}
+
+// NewDiskStore creates dir if needed. A zero ttl or maxBytes means no limit.
+func NewDiskStore(dir string, ttl time.Duration, maxBytes int64) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskStore{dir: dir, ttl: ttl, maxBytes: maxBytes}, nil
+}
+
+// path is the file holding key, which depends on whether the entry has
+// validators.
+func (d *DiskStore) path(key string, revalidatable bool) string {
+	sum := sha256.Sum256([]byte(key))
+	path := filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+	if revalidatable {
+		path += revalidatableSuffix
+	}
+	return path
+}
+
+func (d *DiskStore) expired(createdAt time.Time) bool {
+	return d.ttl > 0 && time.Since(createdAt) > d.ttl
+}
+
+// read loads the entry stored for key, expired or not. Callers must hold d.mu.
+func (d *DiskStore) read(key string) (diskEntry, bool) {
+	for _, revalidatable := range []bool{true, false} {
+		entry, err := readDiskEntry(d.path(key, revalidatable))
+		if err == nil && entry.Key == key {
+			return entry, true
+		}
+	}
+	return diskEntry{}, false
+}
+
+func readDiskEntry(path string) (diskEntry, error) {
+	var entry diskEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(data, &entry)
+	return entry, err
+}
+
+// Get returns the unexpired value stored for key and its validators.
+func (d *DiskStore) Get(key string) ([]byte, Validators, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, ok := d.read(key)
+	if !ok {
+		return nil, Validators{}, false
+	}
+	if d.expired(entry.CreatedAt) {
+		if entry.validators().IsZero() {
+			os.Remove(d.path(key, false))
+		}
+		return nil, Validators{}, false
+	}
+	return entry.Val, entry.validators(), true
+}
+
+// Stale returns the value stored for key even if it has expired, as long as
+// it has validators to revalidate it with.
+func (d *DiskStore) Stale(key string) ([]byte, Validators, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, ok := d.read(key)
+	if !ok || entry.validators().IsZero() {
+		return nil, Validators{}, false
+	}
+	return entry.Val, entry.validators(), true
+}
+
+func (d *DiskStore) Add(key string, val []byte, validators Validators) error {
+	data, err := json.Marshal(diskEntry{
+		Key:          key,
+		CreatedAt:    time.Now(),
+		ETag:         validators.ETag,
+		LastModified: validators.LastModified,
+		Val:          val,
+	})
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// write to a temp file first so a crash never leaves a half-written entry
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	revalidatable := !validators.IsZero()
+	if err := os.Rename(tmp.Name(), d.path(key, revalidatable)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	// an older copy of the entry may sit under the other name
+	os.Remove(d.path(key, !revalidatable))
+	return d.trim()
+}
+
+// trim removes expired files without validators, then the oldest ones until
+// the directory fits in maxBytes. It only looks at names, times and sizes,
+// since it runs on every Add. Callers must hold d.mu.
+func (d *DiskStore) trim() error {
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	var files []fs.FileInfo
+	var total int64
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if d.expired(info.ModTime()) && !strings.HasSuffix(info.Name(), revalidatableSuffix) {
+			os.Remove(filepath.Join(d.dir, info.Name()))
+			continue
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+	if d.maxBytes <= 0 || total <= d.maxBytes {
+		return nil
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, info := range files {
+		if total <= d.maxBytes {
+			break
+		}
+		err := os.Remove(filepath.Join(d.dir, info.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= info.Size()
+	}
+	return nil
+}
+
+// Purge removes the stored entries whose key starts with prefix. An empty
+// prefix removes every entry. Files that don't decode as entries, such as
+// another process's half-written temp files, are left alone.
+func (d *DiskStore) Purge(prefix string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() || strings.HasPrefix(dirEntry.Name(), ".tmp-") {
+			continue
+		}
+		path := filepath.Join(d.dir, dirEntry.Name())
+		entry, err := readDiskEntry(path)
+		if err != nil || !strings.HasPrefix(entry.Key, prefix) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package pokecache
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotModified is returned by a GetOrRevalidate load to report that the
+// stale value it was given is still current.
+var ErrNotModified = errors.New("pokecache: not modified")
+
+// errLoadPanicked is what callers sharing a load get when it panicked; the
+// panic itself goes on in the goroutine that ran the load.
+var errLoadPanicked = errors.New("pokecache: load panicked")
+
+// call is a load in flight, shared by every caller missing the same key.
+type call struct {
+	wg  sync.WaitGroup
+	val []byte
+	err error
+}
+
+// GetOrFetch returns the cached value for key, or runs load and caches its
+// result. Concurrent misses on the same key share a single load and all get
+// its value and error. Failed loads are not cached.
+func (c *Cache) GetOrFetch(key string, load func() ([]byte, error)) ([]byte, error) {
+	return c.GetOrRevalidate(key, func(Validators) ([]byte, Validators, error) {
+		val, err := load()
+		return val, Validators{}, err
+	})
+}
+
+// GetOrRevalidate is GetOrFetch for loads that understand validators. On a
+// miss, load gets the validators of the expired entry for key, if memory or
+// the disk store still holds one, and either returns a new value with its validators
+// or ErrNotModified, in which case the stale value is renewed and returned.
+func (c *Cache) GetOrRevalidate(key string, load func(stale Validators) ([]byte, Validators, error)) ([]byte, error) {
+	if val, ok := c.Get(key); ok {
+		return val, nil
+	}
+	c.flightMu.Lock()
+	if inFlight, ok := c.inFlight[key]; ok {
+		c.flightMu.Unlock()
+		inFlight.wg.Wait()
+		c.mu.Lock()
+		c.stats.Shared++
+		c.mu.Unlock()
+		return inFlight.val, inFlight.err
+	}
+	newCall := &call{err: errLoadPanicked}
+	newCall.wg.Add(1)
+	c.inFlight[key] = newCall
+	c.flightMu.Unlock()
+	// deferred so a panicking load doesn't leave waiters blocked forever
+	defer func() {
+		c.flightMu.Lock()
+		delete(c.inFlight, key)
+		c.flightMu.Unlock()
+		newCall.wg.Done()
+	}()
+
+	newCall.val, newCall.err = c.load(key, load)
+	return newCall.val, newCall.err
+}
+
+func (c *Cache) load(key string, load func(stale Validators) ([]byte, Validators, error)) ([]byte, error) {
+	staleVal, stale := c.stale(key)
+	val, validators, err := load(stale)
+	if errors.Is(err, ErrNotModified) && !stale.IsZero() {
+		val, validators, err = staleVal, stale, nil
+		c.mu.Lock()
+		c.stats.Revalidations++
+		c.mu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// a failing disk write still leaves the value in memory, which is all
+	// the caller needs; rewriting a revalidated entry renews its createdAt
+	c.AddValidated(key, val, validators)
+	return val, nil
+}
+
+// stale returns the expired value kept for key and its validators, from
+// memory or else from the disk store.
+func (c *Cache) stale(key string) ([]byte, Validators) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && !entry.validators.IsZero() {
+		return entry.val, entry.validators
+	}
+	if c.disk != nil {
+		val, validators, _ := c.disk.Stale(key)
+		return val, validators
+	}
+	return nil, Validators{}
+}
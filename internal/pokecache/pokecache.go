@@ -1,61 +1,221 @@
 package pokecache
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
 )
 
 type cacheEntry struct {
-	createdAt time.Time
-	val       []byte
+	createdAt  time.Time
+	val        []byte
+	validators Validators
+	// expired entries are only kept for their validators, to revalidate
+	// with; Get doesn't return them
+	expired bool
+	// elem is the entry's node in the LRU list, whose value is the key
+	elem *list.Element
 }
 
 type Cache struct {
 	entries map[string]cacheEntry
 	mu      sync.RWMutex
+	disk    *DiskStore
+	// lru orders keys from most to least recently used
+	lru        *list.List
+	bytes      int64
+	maxBytes   int64
+	maxEntries int
+	// ctx stops the reaper; cancel and reaperDone let Close wait for it
+	ctx        context.Context
+	cancel     context.CancelFunc
+	reaperDone chan struct{}
+	stats      Stats
+	// inFlight holds the loads running in GetOrFetch, guarded by flightMu
+	inFlight map[string]*call
+	flightMu sync.Mutex
 }
 
-func NewCache(interval time.Duration) *Cache {
+// Option configures a Cache built by NewCache.
+type Option func(*Cache)
+
+// WithDiskStore backs the in-memory entries with disk, so they survive
+// restarts and outlive the in-memory interval.
+func WithDiskStore(disk *DiskStore) Option {
+	return func(c *Cache) {
+		c.disk = disk
+	}
+}
+
+// WithContext ties the reaper goroutine to ctx: it stops when ctx is done,
+// as if Close had been called.
+func WithContext(ctx context.Context) Option {
+	return func(c *Cache) {
+		c.ctx = ctx
+	}
+}
+
+// WithMaxBytes bounds the total size of the in-memory values. The least
+// recently used entries are evicted to make room. Zero means no limit.
+func WithMaxBytes(maxBytes int64) Option {
+	return func(c *Cache) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// WithMaxEntries bounds the number of in-memory entries, evicting the least
+// recently used ones. Zero means no limit.
+func WithMaxEntries(maxEntries int) Option {
+	return func(c *Cache) {
+		c.maxEntries = maxEntries
+	}
+}
+
+func NewCache(interval time.Duration, opts ...Option) *Cache {
 	newCache := Cache{}
 	newCache.entries = make(map[string]cacheEntry)
+	newCache.lru = list.New()
+	newCache.inFlight = make(map[string]*call)
+	newCache.ctx = context.Background()
+	for _, opt := range opts {
+		opt(&newCache)
+	}
+	newCache.ctx, newCache.cancel = context.WithCancel(newCache.ctx)
+	newCache.reaperDone = make(chan struct{})
 	go newCache.reapLoop(interval)
 	return &newCache
 }
 
+// Close stops the reaper goroutine and waits for it to exit. Entries stay
+// readable; they just no longer expire. Close can be called more than once.
+func (c *Cache) Close() error {
+	c.cancel()
+	<-c.reaperDone
+	return nil
+}
+
 func (c *Cache) reapLoop(interval time.Duration) {
+	defer close(c.reaperDone)
 	ticker := time.NewTicker(interval)
-	for range ticker.C {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
 		c.mu.Lock()
 		if c.entries == nil {
+			c.mu.Unlock()
 			continue
 		}
 		for key, entry := range c.entries {
-			// need to check that UNIT
-			if time.Since(entry.createdAt) > interval {
-				delete(c.entries, key)
+			if entry.expired || time.Since(entry.createdAt) <= interval {
+				continue
+			}
+			c.stats.Expirations++
+			if entry.validators.IsZero() {
+				c.remove(key)
+				continue
 			}
+			entry.expired = true
+			c.entries[key] = entry
 		}
 		c.mu.Unlock()
 	}
 }
 
+// remove drops key from memory. Callers must hold c.mu.
+func (c *Cache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.lru.Remove(entry.elem)
+	c.bytes -= int64(len(entry.val))
+	delete(c.entries, key)
+}
+
+// set stores val in memory as the most recently used entry, then evicts from
+// the back of the LRU list until the limits are met. Callers must hold c.mu.
+func (c *Cache) set(key string, val []byte, validators Validators) {
+	c.remove(key)
+	c.entries[key] = cacheEntry{
+		createdAt:  time.Now(),
+		val:        val,
+		validators: validators,
+		elem:       c.lru.PushFront(key),
+	}
+	c.bytes += int64(len(val))
+	for c.lru.Len() > 1 && c.overLimit() {
+		c.remove(c.lru.Back().Value.(string))
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) overLimit() bool {
+	return (c.maxBytes > 0 && c.bytes > c.maxBytes) ||
+		(c.maxEntries > 0 && c.lru.Len() > c.maxEntries)
+}
+
 func (c *Cache) Add(key string, val []byte) error {
+	return c.AddValidated(key, val, Validators{})
+}
+
+// AddValidated stores val along with the validators its response came with,
+// so it can be revalidated instead of downloaded again once it expires.
+func (c *Cache) AddValidated(key string, val []byte, validators Validators) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	newEntry := cacheEntry{}
-	newEntry.createdAt = time.Now()
-	newEntry.val = val
-	c.entries[key] = newEntry
+	c.set(key, val, validators)
+	c.mu.Unlock()
+	if c.disk != nil {
+		return c.disk.Add(key, val, validators)
+	}
 	return nil
 }
 
 func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	ok = ok && !entry.expired
+	if ok {
+		c.lru.MoveToFront(entry.elem)
+		c.stats.Hits++
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.val, true
+	}
+	var val []byte
+	var validators Validators
+	if c.disk != nil {
+		val, validators, ok = c.disk.Get(key)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	val := c.entries[key].val
-	if val == nil {
+	if !ok {
+		c.stats.Misses++
 		return nil, false
-	} else {
-		return val, true
 	}
+	// promote to memory so the next lookup skips the disk
+	c.stats.DiskHits++
+	c.set(key, val, validators)
+	return val, true
+}
+
+// Contains reports whether key has an unexpired value in memory or on disk,
+// without counting as a lookup in the stats.
+func (c *Cache) Contains(key string) bool {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && !entry.expired {
+		return true
+	}
+	if c.disk == nil {
+		return false
+	}
+	_, _, ok = c.disk.Get(key)
+	return ok
 }
@@ -0,0 +1,101 @@
+package pokecache
+
+import (
+	"strings"
+	"time"
+)
+
+// Stats counts cache activity since the cache was created.
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	DiskHits    uint64 `json:"disk_hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	// Shared counts GetOrFetch callers served by another caller's load
+	Shared uint64 `json:"shared"`
+	// Revalidations counts expired entries the server confirmed unchanged
+	Revalidations uint64 `json:"revalidations"`
+	Entries       int    `json:"entries"`
+	// Stale counts expired entries kept in memory only to be revalidated
+	Stale int   `json:"stale"`
+	Bytes int64 `json:"bytes"`
+}
+
+// HitRatio is the share of lookups answered from memory or disk.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.DiskHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.DiskHits) / float64(total)
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	for _, entry := range c.entries {
+		if entry.expired {
+			stats.Stale++
+		} else {
+			stats.Entries++
+		}
+	}
+	stats.Bytes = c.bytes
+	return stats
+}
+
+type EntryInfo struct {
+	Key  string
+	Size int
+	Age  time.Duration
+}
+
+// Entries describes the in-memory entries, most recently used first. Stale
+// entries kept only for revalidation are left out.
+func (c *Cache) Entries() []EntryInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	infos := make([]EntryInfo, 0, c.lru.Len())
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		entry := c.entries[key]
+		if entry.expired {
+			continue
+		}
+		infos = append(infos, EntryInfo{Key: key, Size: len(entry.val), Age: time.Since(entry.createdAt)})
+	}
+	return infos
+}
+
+// Clear removes every entry, from memory and from the disk store.
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	for key := range c.entries {
+		c.remove(key)
+	}
+	c.mu.Unlock()
+	if c.disk != nil {
+		return c.disk.Purge("")
+	}
+	return nil
+}
+
+// Purge removes the entries whose key starts with prefix, from memory and
+// from the disk store, and returns how many were in memory.
+func (c *Cache) Purge(prefix string) (int, error) {
+	c.mu.Lock()
+	removed := 0
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(key)
+			removed++
+		}
+	}
+	c.mu.Unlock()
+	if c.disk != nil {
+		return removed, c.disk.Purge(prefix)
+	}
+	return removed, nil
+}
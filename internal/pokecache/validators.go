@@ -0,0 +1,14 @@
+package pokecache
+
+// Validators are the HTTP validators a cached response came with. They let a
+// client ask the server whether an expired entry is still current with
+// If-None-Match and If-Modified-Since instead of downloading it again.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero reports whether there is nothing to revalidate with.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
@@ -0,0 +1,113 @@
+// Package prefetch warms the cache in the background by fetching a list of
+// resources with a bounded number of workers and a request rate limit.
+package prefetch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Prefetcher struct {
+	workers  int
+	interval time.Duration
+	fetch    func(ctx context.Context, name string) error
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	// running tracks the runs and their fetches, including cancelled ones
+	// still winding down
+	running sync.WaitGroup
+}
+
+// New returns a Prefetcher running fetch with at most workers calls at once
+// and at most perSecond calls started per second. A perSecond of 0 means no
+// rate limit. fetch should give up when its context is cancelled.
+func New(workers int, perSecond float64, fetch func(ctx context.Context, name string) error) *Prefetcher {
+	if workers < 1 {
+		workers = 1
+	}
+	var interval time.Duration
+	if perSecond > 0 {
+		interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return &Prefetcher{workers: workers, interval: interval, fetch: fetch}
+}
+
+// Start fetches names in the background, first stopping the previous run.
+// Fetch errors are ignored: a failed prefetch just means a later cache miss.
+func (p *Prefetcher) Start(names []string) {
+	p.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	p.mu.Lock()
+	p.cancel, p.done = cancel, done
+	p.mu.Unlock()
+	p.running.Add(1)
+	go p.run(ctx, names, done)
+}
+
+// Stop cancels the current run and waits for its fetches to give up, so
+// nothing writes to the cache after it returns. It is safe to call when
+// nothing runs.
+func (p *Prefetcher) Stop() error {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	p.running.Wait()
+	return nil
+}
+
+// Wait blocks until the current run has fetched everything or was stopped.
+func (p *Prefetcher) Wait() {
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+func (p *Prefetcher) run(ctx context.Context, names []string, done chan struct{}) {
+	defer p.running.Done()
+	defer close(done)
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				p.fetch(ctx, name)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(queue)
+
+	var tick <-chan time.Time
+	if p.interval > 0 {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for i, name := range names {
+		if i > 0 && tick != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case queue <- name:
+		}
+	}
+}
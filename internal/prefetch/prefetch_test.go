@@ -0,0 +1,90 @@
+package prefetch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPrefetchBoundsWorkers(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	var mu sync.Mutex
+	fetched := map[string]bool{}
+	names := []string{"pikachu", "tentacool", "shellos", "buizel", "gastrodon"}
+	started := make(chan struct{}, len(names))
+	release := make(chan struct{})
+	p := New(2, 0, func(_ context.Context, name string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		started <- struct{}{}
+		<-release
+		mu.Lock()
+		fetched[name] = true
+		mu.Unlock()
+		return nil
+	})
+	p.Start(names)
+	// both workers are busy before any fetch finishes
+	<-started
+	<-started
+	close(release)
+	p.Wait()
+
+	if len(fetched) != len(names) {
+		t.Errorf("expected %d pokemon fetched, got %v", len(names), fetched)
+	}
+	if maxRunning.Load() != 2 {
+		t.Errorf("expected 2 concurrent fetches at most, got %d", maxRunning.Load())
+	}
+}
+
+func TestPrefetchStartCancelsPreviousRun(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	started := make(chan struct{}, 1)
+	p := New(1, 0, func(ctx context.Context, name string) error {
+		if name == "a1" {
+			started <- struct{}{}
+			// a slow request, given up when the run is cancelled
+			<-ctx.Done()
+		}
+		mu.Lock()
+		fetched = append(fetched, name)
+		mu.Unlock()
+		return ctx.Err()
+	})
+	p.Start([]string{"a1", "a2", "a3"})
+	<-started
+	p.Start([]string{"b1"})
+	p.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fetched) != 2 || fetched[0] != "a1" || fetched[1] != "b1" {
+		t.Errorf("expected a1 to be cancelled and then b1 fetched, got %v", fetched)
+	}
+}
+
+func TestPrefetchStopWaitsForFetches(t *testing.T) {
+	var finished atomic.Bool
+	started := make(chan struct{})
+	p := New(1, 0, func(ctx context.Context, name string) error {
+		close(started)
+		<-ctx.Done()
+		finished.Store(true)
+		return ctx.Err()
+	})
+	p.Start([]string{"pikachu"})
+	<-started
+	p.Stop()
+	if !finished.Load() {
+		t.Error("expected Stop to wait for the cancelled fetch")
+	}
+}
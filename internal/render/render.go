@@ -0,0 +1,77 @@
+// Package render serializes command results in the output format picked by
+// the user.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+type Format string
+
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Text, JSON, YAML, Table:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected one of text, json, yaml, table", s)
+}
+
+// Texter is implemented by results with a human-readable form. Results that
+// don't implement it are printed with fmt.Println in text mode.
+type Texter interface {
+	WriteText(w io.Writer) error
+}
+
+// Tabler is implemented by results that can be shown as rows and columns.
+// Results that don't implement it fall back to text in table mode.
+type Tabler interface {
+	Table() (header []string, rows [][]string)
+}
+
+// Render writes v to w in the given format. A nil v writes nothing.
+func Render(w io.Writer, format Format, v any) error {
+	if v == nil {
+		return nil
+	}
+	switch format {
+	case JSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case YAML:
+		return writeYAML(w, v)
+	case Table:
+		if t, ok := v.(Tabler); ok {
+			return writeTable(w, t)
+		}
+	}
+	if t, ok := v.(Texter); ok {
+		return t.WriteText(w)
+	}
+	_, err := fmt.Fprintln(w, v)
+	return err
+}
+
+func writeTable(w io.Writer, t Tabler) error {
+	header, rows := t.Table()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+type testResult struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+	Stats []struct {
+		Name string `json:"name"`
+		Base int    `json:"base"`
+	} `json:"stats"`
+	Empty []string `json:"empty"`
+}
+
+func (r testResult) WriteText(w io.Writer) error {
+	_, err := io.WriteString(w, "Name: "+r.Name+"\n")
+	return err
+}
+
+func (r testResult) Table() ([]string, [][]string) {
+	return []string{"name", "types"}, [][]string{{r.Name, strings.Join(r.Types, ",")}}
+}
+
+func TestRender(t *testing.T) {
+	result := testResult{Name: "pikachu", Types: []string{"electric"}, Empty: []string{}}
+	result.Stats = append(result.Stats, struct {
+		Name string `json:"name"`
+		Base int    `json:"base"`
+	}{Name: "hp", Base: 35})
+
+	cases := map[Format]string{
+		Text: "Name: pikachu\n",
+		JSON: `{
+  "name": "pikachu",
+  "types": [
+    "electric"
+  ],
+  "stats": [
+    {
+      "name": "hp",
+      "base": 35
+    }
+  ],
+  "empty": []
+}
+`,
+		YAML: `empty: []
+name: pikachu
+stats:
+  - base: 35
+    name: hp
+types:
+  - electric
+`,
+		Table: "NAME     TYPES\npikachu  electric\n",
+	}
+	for format, expected := range cases {
+		var b strings.Builder
+		if err := Render(&b, format, result); err != nil {
+			t.Errorf("%s: unexpected error: %v", format, err)
+			continue
+		}
+		if b.String() != expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", format, expected, b.String())
+		}
+	}
+}
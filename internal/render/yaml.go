@@ -0,0 +1,134 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// writeYAML goes through encoding/json so the json struct tags name the YAML
+// keys too, then prints the generic value as block-style YAML.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	var b strings.Builder
+	switch generic.(type) {
+	case map[string]any, []any:
+		writeYAMLValue(&b, generic, 0)
+	default:
+		b.WriteString(yamlScalar(generic) + "\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeYAMLValue(b *strings.Builder, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			writeYAMLEntry(b, pad+yamlScalar(key)+":", v[key], indent)
+		}
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			if isCollection(item) {
+				// render the item one level deeper, then turn the
+				// indentation of its first line into the dash
+				var nested strings.Builder
+				writeYAMLValue(&nested, item, indent+1)
+				b.WriteString(pad + "- " + strings.TrimPrefix(nested.String(), pad+"  "))
+				continue
+			}
+			writeYAMLEntry(b, pad+"-", item, indent)
+		}
+	}
+}
+
+func isCollection(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+// writeYAMLEntry writes a key or list item prefix followed by its value,
+// nesting collections on the following lines.
+func writeYAMLEntry(b *strings.Builder, prefix string, v any, indent int) {
+	switch child := v.(type) {
+	case map[string]any:
+		if len(child) == 0 {
+			b.WriteString(prefix + " {}\n")
+			return
+		}
+		b.WriteString(prefix + "\n")
+		writeYAMLValue(b, child, indent+1)
+	case []any:
+		if len(child) == 0 {
+			b.WriteString(prefix + " []\n")
+			return
+		}
+		b.WriteString(prefix + "\n")
+		writeYAMLValue(b, child, indent+1)
+	default:
+		b.WriteString(prefix + " " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if needsQuoting(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+func needsQuoting(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t")
+}
@@ -0,0 +1,202 @@
+// Package termart draws small images, such as pokemon sprites, in a terminal.
+package termart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+)
+
+type Mode int
+
+const (
+	// TrueColor draws two pixels per cell with '▀' and 24-bit colors.
+	TrueColor Mode = iota
+	// Color256 is TrueColor with colors mapped to the xterm 256-color palette.
+	Color256
+	// ASCII draws one pixel per cell with characters of growing density.
+	ASCII
+)
+
+// ParseMode reads a mode name: truecolor, 256 or ascii.
+func ParseMode(name string) (Mode, error) {
+	switch strings.ToLower(name) {
+	case "truecolor", "24bit":
+		return TrueColor, nil
+	case "256":
+		return Color256, nil
+	case "ascii":
+		return ASCII, nil
+	}
+	return 0, fmt.Errorf("unknown art mode %q, use truecolor, 256 or ascii", name)
+}
+
+// DetectMode picks the richest mode the terminal advertises through
+// COLORTERM and TERM. Without a terminal, or with NO_COLOR set, it is ASCII.
+func DetectMode(terminal bool) Mode {
+	term := os.Getenv("TERM")
+	switch {
+	case !terminal || os.Getenv("NO_COLOR") != "" || term == "dumb":
+		return ASCII
+	case os.Getenv("COLORTERM") == "truecolor" || os.Getenv("COLORTERM") == "24bit":
+		return TrueColor
+	case strings.Contains(term, "256color"):
+		return Color256
+	}
+	return ASCII
+}
+
+// opaque is the alpha below which a pixel counts as background.
+const opaque = 0x8000
+
+// asciiRamp goes from faint to dense.
+const asciiRamp = ".:-=+*#%@"
+
+// Render draws img on w, cropped to its visible pixels and shrunk to at most
+// maxWidth columns.
+func Render(w io.Writer, img image.Image, mode Mode, maxWidth int) error {
+	img = crop(img)
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil
+	}
+	step := 1
+	if maxWidth > 0 && bounds.Dx() > maxWidth {
+		step = (bounds.Dx() + maxWidth - 1) / maxWidth
+	}
+	var out strings.Builder
+	if mode == ASCII {
+		// cells are about twice as tall as wide, so skip every other row
+		for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 * step {
+			var line []byte
+			for x := bounds.Min.X; x < bounds.Max.X; x += step {
+				line = append(line, asciiCell(img.At(x, y)))
+			}
+			out.WriteString(strings.TrimRight(string(line), " ") + "\n")
+		}
+	} else {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 * step {
+			// codes fully set the colors, so repeating them is wasted output
+			lastCodes := ""
+			for x := bounds.Min.X; x < bounds.Max.X; x += step {
+				top := img.At(x, y)
+				bottom := color.Color(color.Transparent)
+				if y+step < bounds.Max.Y {
+					bottom = img.At(x, y+step)
+				}
+				codes, glyph := halfBlock(top, bottom, mode)
+				if codes != lastCodes {
+					out.WriteString(codes)
+					lastCodes = codes
+				}
+				out.WriteString(glyph)
+			}
+			out.WriteString("\x1b[0m\n")
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// crop trims the fully transparent border sprites usually have.
+func crop(img image.Image) image.Image {
+	bounds := img.Bounds()
+	visible := image.Rectangle{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a >= opaque {
+				visible = visible.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(visible)
+	}
+	return img
+}
+
+// halfBlock returns the escape codes and glyph drawing top over bottom in
+// one cell.
+func halfBlock(top, bottom color.Color, mode Mode) (codes, glyph string) {
+	_, _, _, topA := top.RGBA()
+	_, _, _, bottomA := bottom.RGBA()
+	switch {
+	case topA < opaque && bottomA < opaque:
+		return "\x1b[0m", " "
+	case bottomA < opaque:
+		return "\x1b[0m" + colorCode(top, mode, false), "▀"
+	case topA < opaque:
+		return "\x1b[0m" + colorCode(bottom, mode, false), "▄"
+	}
+	return colorCode(top, mode, false) + colorCode(bottom, mode, true), "▀"
+}
+
+func colorCode(c color.Color, mode Mode, background bool) string {
+	layer := 38
+	if background {
+		layer = 48
+	}
+	r, g, b, _ := c.RGBA()
+	r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
+	if mode == Color256 {
+		return fmt.Sprintf("\x1b[%d;5;%dm", layer, xterm256(r8, g8, b8))
+	}
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, r8, g8, b8)
+}
+
+// cubeLevels are the channel values of the xterm 6x6x6 color cube.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// xterm256 returns the palette index nearest to a color, from the color cube
+// or the grayscale ramp.
+func xterm256(r, g, b uint8) int {
+	nearest := func(v uint8) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(int(v)-level) < abs(int(v)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearest(r), nearest(g), nearest(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := sq(int(r)-cubeLevels[ri]) + sq(int(g)-cubeLevels[gi]) + sq(int(b)-cubeLevels[bi])
+
+	// the ramp runs from 8 to 238 in steps of 10
+	avg := (int(r) + int(g) + int(b)) / 3
+	grayIndex := min(max((avg-3)/10, 0), 23)
+	gray := 8 + 10*grayIndex
+	grayDist := sq(int(r)-gray) + sq(int(g)-gray) + sq(int(b)-gray)
+	if grayDist < cubeDist {
+		return 232 + grayIndex
+	}
+	return cube
+}
+
+func asciiCell(c color.Color) byte {
+	r, g, b, a := c.RGBA()
+	if a < opaque {
+		return ' '
+	}
+	// darker pixels get denser characters
+	luma := (299*r + 587*g + 114*b) / 1000
+	darkness := 0xffff - luma
+	return asciiRamp[int(darkness)*(len(asciiRamp)-1)/0xffff]
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sq(v int) int {
+	return v * v
+}
@@ -0,0 +1,88 @@
+package termart
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// testImage is 4x4 with a transparent border around a 2x2 block: red over
+// blue on the left, white over black on the right.
+func testImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.NRGBA{255, 0, 0, 255})
+	img.Set(1, 2, color.NRGBA{0, 0, 255, 255})
+	img.Set(2, 1, color.NRGBA{255, 255, 255, 255})
+	img.Set(2, 2, color.NRGBA{0, 0, 0, 255})
+	return img
+}
+
+func TestRenderTrueColor(t *testing.T) {
+	var out strings.Builder
+	if err := Render(&out, testImage(), TrueColor, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀" +
+		"\x1b[38;2;255;255;255m\x1b[48;2;0;0;0m▀\x1b[0m\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestRender256(t *testing.T) {
+	var out strings.Builder
+	if err := Render(&out, testImage(), Color256, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "\x1b[38;5;196m\x1b[48;5;21m▀\x1b[38;5;231m\x1b[48;5;16m▀\x1b[0m\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestRenderASCII(t *testing.T) {
+	var out strings.Builder
+	if err := Render(&out, testImage(), ASCII, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// only the top row of each pair of rows is drawn: red, then white
+	if out.String() != "*.\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestRenderShrinks(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 2))
+	for x := 0; x < 10; x++ {
+		img.Set(x, 0, color.NRGBA{0, 0, 0, 255})
+		img.Set(x, 1, color.NRGBA{0, 0, 0, 255})
+	}
+	var out strings.Builder
+	if err := Render(&out, img, ASCII, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "@@@@@\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestXterm256Grays(t *testing.T) {
+	if got := xterm256(128, 128, 128); got != 244 {
+		t.Errorf("expected mid gray on the grayscale ramp, got %d", got)
+	}
+}
+
+func TestRenderSkipsRepeatedCodes(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	for x := 0; x < 3; x++ {
+		img.Set(x, 0, color.NRGBA{255, 0, 0, 255})
+	}
+	var out strings.Builder
+	if err := Render(&out, img, TrueColor, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "\x1b[0m\x1b[38;2;255;0;0m▀▀▀\x1b[0m\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,113 @@
+// Package typechart computes type effectiveness from the damage relations
+// of the PokeAPI /type endpoint.
+package typechart
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+// Types lists the attacking types, in the order the games show them.
+var Types = []string{
+	"normal", "fire", "water", "electric", "grass", "ice",
+	"fighting", "poison", "ground", "flying", "psychic", "bug",
+	"rock", "ghost", "dragon", "dark", "steel", "fairy",
+}
+
+func IsType(name string) bool {
+	return slices.Contains(Types, name)
+}
+
+// TypeSource fetches a type. *pokeapi.Client satisfies it.
+type TypeSource interface {
+	GetType(name string) (pokeapi.TypeAPIResponse, error)
+}
+
+// Chart fetches types on first use and remembers their relations.
+type Chart struct {
+	source TypeSource
+	mu     sync.Mutex
+	// attacking[attacker][defender] is the multiplier when it differs from 1
+	attacking map[string]map[string]float64
+}
+
+func New(source TypeSource) *Chart {
+	return &Chart{source: source, attacking: map[string]map[string]float64{}}
+}
+
+func (c *Chart) relations(attacker string) (map[string]float64, error) {
+	c.mu.Lock()
+	rel, ok := c.attacking[attacker]
+	c.mu.Unlock()
+	if ok {
+		return rel, nil
+	}
+	// fetch without the lock so lookups of other types don't queue behind
+	// it; the client already shares concurrent fetches of the same type
+	t, err := c.source.GetType(attacker)
+	if err != nil {
+		return nil, err
+	}
+	rel = map[string]float64{}
+	for _, d := range t.DamageRelations.DoubleDamageTo {
+		rel[d.Name] = 2
+	}
+	for _, d := range t.DamageRelations.HalfDamageTo {
+		rel[d.Name] = 0.5
+	}
+	for _, d := range t.DamageRelations.NoDamageTo {
+		rel[d.Name] = 0
+	}
+	c.mu.Lock()
+	c.attacking[attacker] = rel
+	c.mu.Unlock()
+	return rel, nil
+}
+
+// Effectiveness is the combined multiplier of an attacking type against all
+// the types of a defender, e.g. 4 for electric against water/flying.
+func (c *Chart) Effectiveness(attacker string, defenderTypes []string) (float64, error) {
+	rel, err := c.relations(attacker)
+	if err != nil {
+		return 1, err
+	}
+	multiplier := 1.0
+	for _, defender := range defenderTypes {
+		if m, ok := rel[defender]; ok {
+			multiplier *= m
+		}
+	}
+	return multiplier, nil
+}
+
+// Weaknesses returns the multiplier of every attacking type against the
+// defender types, in the order of Types. The types are fetched concurrently,
+// and any failure fails the whole list.
+func (c *Chart) Weaknesses(defenderTypes []string) ([]Multiplier, error) {
+	multipliers := make([]Multiplier, len(Types))
+	errs := make([]error, len(Types))
+	var wg sync.WaitGroup
+	for i, attacker := range Types {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := c.Effectiveness(attacker, defenderTypes)
+			multipliers[i] = Multiplier{Type: attacker, Multiplier: m}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return multipliers, nil
+}
+
+type Multiplier struct {
+	Type       string  `json:"type"`
+	Multiplier float64 `json:"multiplier"`
+}
@@ -0,0 +1,69 @@
+package typechart
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+type fakeSource map[string][3][]string
+
+func (f fakeSource) GetType(name string) (pokeapi.TypeAPIResponse, error) {
+	var t pokeapi.TypeAPIResponse
+	relations, ok := f[name]
+	if !ok {
+		return t, errors.New("unknown type")
+	}
+	t.Name = name
+	for _, n := range relations[0] {
+		t.DamageRelations.DoubleDamageTo = append(t.DamageRelations.DoubleDamageTo, pokeapi.NamedAPIResource{Name: n})
+	}
+	for _, n := range relations[1] {
+		t.DamageRelations.HalfDamageTo = append(t.DamageRelations.HalfDamageTo, pokeapi.NamedAPIResource{Name: n})
+	}
+	for _, n := range relations[2] {
+		t.DamageRelations.NoDamageTo = append(t.DamageRelations.NoDamageTo, pokeapi.NamedAPIResource{Name: n})
+	}
+	return t, nil
+}
+
+func TestEffectiveness(t *testing.T) {
+	chart := New(fakeSource{
+		"electric": {{"water", "flying"}, {"grass", "electric", "dragon"}, {"ground"}},
+		"ice":      {{"grass", "ground", "flying", "dragon"}, {"fire", "water", "ice", "steel"}, nil},
+	})
+	cases := []struct {
+		attacker string
+		defender []string
+		expected float64
+	}{
+		{"electric", []string{"water", "flying"}, 4},
+		{"electric", []string{"water", "ground"}, 0},
+		{"electric", []string{"grass", "dragon"}, 0.25},
+		{"ice", []string{"water", "flying"}, 1},
+		{"electric", []string{"normal"}, 1},
+	}
+	for _, c := range cases {
+		m, err := chart.Effectiveness(c.attacker, c.defender)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if m != c.expected {
+			t.Errorf("%s against %v: expected %v, got %v", c.attacker, c.defender, c.expected, m)
+		}
+	}
+	if _, err := chart.Effectiveness("shadow", []string{"normal"}); err == nil {
+		t.Errorf("expected an error for an unknown type")
+	}
+}
+
+func TestWeaknessesFailWhole(t *testing.T) {
+	chart := New(fakeSource{
+		"electric": {{"water", "flying"}, {"grass", "electric", "dragon"}, {"ground"}},
+	})
+	if m, err := chart.Weaknesses([]string{"water"}); err == nil {
+		t.Errorf("expected the unknown types to fail the list, got %v", m)
+	}
+}
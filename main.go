@@ -1,671 +1,628 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/tholho/pokedexcli/internal/battle"
+	"github.com/tholho/pokedexcli/internal/dexquery"
+	"github.com/tholho/pokedexcli/internal/lineedit"
+	"github.com/tholho/pokedexcli/internal/pokeapi"
 	"github.com/tholho/pokedexcli/internal/pokecache"
+	"github.com/tholho/pokedexcli/internal/prefetch"
+	"github.com/tholho/pokedexcli/internal/render"
+	"github.com/tholho/pokedexcli/internal/typechart"
 )
 
 type cliCommand struct {
 	name        string
+	usage       string
 	description string
-	callback    func(*config, *pokecache.Cache, string) error
+	minArgs     int
+	maxArgs     int // -1 for no limit
+	flags       map[string]string
+	// changesState marks commands that catch, save or drop something, which
+	// typos are never corrected into
+	changesState bool
+	callback     func(*config, commandArgs) (any, error)
 }
 
 type config struct {
+	client   *pokeapi.Client
+	cache    *pokecache.Cache
 	next     string
 	previous string
 	area     string
-	pokedex  map[string]pokemonAPIResponse
-}
-
-type locationAreaAPIResponse struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous string `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
-}
-
-type locationAPIResponse struct {
-	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"encounter_method"`
-		VersionDetails []struct {
-			Rate    int `json:"rate"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"encounter_method_rates"`
-	GameIndex int `json:"game_index"`
-	ID        int `json:"id"`
-	Location  struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance"`
-				ConditionValues []any `json:"condition_values"`
-				MaxLevel        int   `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"pokemon_encounters"`
-}
-
-type pokemonAPIResponse struct {
-	Abilities []struct {
-		Ability struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
-	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Cries          struct {
-		Latest string `json:"latest"`
-		Legacy string `json:"legacy"`
-	} `json:"cries"`
-	Forms []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height                 int    `json:"height"`
-	HeldItems              []any  `json:"held_items"`
-	ID                     int    `json:"id"`
-	IsDefault              bool   `json:"is_default"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []any  `json:"past_abilities"`
-	PastTypes     []any  `json:"past_types"`
-	Species       struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string `json:"back_default"`
-		BackFemale       any    `json:"back_female"`
-		BackShiny        string `json:"back_shiny"`
-		BackShinyFemale  any    `json:"back_shiny_female"`
-		FrontDefault     string `json:"front_default"`
-		FrontFemale      any    `json:"front_female"`
-		FrontShiny       string `json:"front_shiny"`
-		FrontShinyFemale any    `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string `json:"front_default"`
-				FrontFemale  any    `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
-				BackDefault      string `json:"back_default"`
-				BackFemale       any    `json:"back_female"`
-				BackShiny        string `json:"back_shiny"`
-				BackShinyFemale  any    `json:"back_shiny_female"`
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string `json:"back_default"`
-						BackFemale       any    `json:"back_female"`
-						BackShiny        string `json:"back_shiny"`
-						BackShinyFemale  any    `json:"back_shiny_female"`
-						FrontDefault     string `json:"front_default"`
-						FrontFemale      any    `json:"front_female"`
-						FrontShiny       string `json:"front_shiny"`
-						FrontShinyFemale any    `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+	location pokeapi.LocationAPIResponse
+	// nameIndex caches the names of every resource of a kind, keyed by
+	// resource, for resolveName
+	nameIndex map[string][]string
+	// seenAreas holds the location areas listed by map and mapb, for
+	// completion
+	seenAreas map[string]bool
+	sandbox   bool
+	pokedex   map[string]caughtPokemon
+	// pokedexLoaded is false when the save file exists but failed to load,
+	// which turns autosaving off until an explicit save
+	pokedexLoaded bool
+	savePath      string
+	// interactive is false when running from argv, -c, -f or a pipe, in
+	// which case prompts and banners are left out.
+	interactive bool
+	output      render.Format
+	// battle is the fight in progress, if any. Catching its wild pokemon
+	// gets easier as it loses HP.
+	battle    *battle.Battle
+	typeChart *typechart.Chart
+	// prefetcher, when enabled, fetches the pokemon of an explored area
+	// in the background
+	prefetcher *prefetch.Prefetcher
+	// offline is set when reading from the mirror in offlineDir, which sync
+	// fills
+	offline    bool
+	offlineDir string
+	// history holds the lines entered in the REPL, loaded from and saved to
+	// a file; it is nil when disabled
+	history *lineedit.History
+	// replaying is set while history re-runs an entry
+	replaying bool
+	// game is the version explore, catch and battle stick to, all of them
+	// when empty
+	game string
+	// cleanups run on shutdown, see onShutdown
+	cleanups     []func() error
+	shutdownOnce sync.Once
+	// ctx is cancelled when a signal asks the program to stop, see
+	// handleSignals; busy is held while a command runs
+	ctx    context.Context
+	cancel context.CancelFunc
+	busy   sync.Mutex
 }
 
 var cmdRegistry map[string]cliCommand
 
-func commandExit(config *config, cache *pokecache.Cache, userParam string) error {
-	_, err := fmt.Print("Closing the Pokedex... Goodbye!\n")
-	if err != nil {
-		return err
+func commandExit(config *config, args commandArgs) (any, error) {
+	if config.interactive {
+		fmt.Print("Closing the Pokedex... Goodbye!\n")
 	}
-	os.Exit(0)
-	return nil
+	return nil, errExit
 }
 
-func commandHelp(config *config, cache *pokecache.Cache, userParam string) error {
-	cmdDescriptions := ""
-	for item := range cmdRegistry {
-		cmdDescriptions = cmdDescriptions + "\n" + cmdRegistry[item].name + ": " + cmdRegistry[item].description
+func commandHelp(config *config, args commandArgs) (any, error) {
+	if name := args.arg(0); name != "" {
+		cmd, err := resolveCommand(config, strings.ToLower(name))
+		if err != nil {
+			return nil, err
+		}
+		return cmd.help(), nil
 	}
-	_, err := fmt.Println("Welcome to the Pokedex!", "Usage:", "", cmdDescriptions)
-	if err != nil {
-		return err
+	var res helpResult
+	for _, item := range sortedKeys(cmdRegistry) {
+		res.Commands = append(res.Commands, cmdRegistry[item].help())
 	}
-	return nil
+	return res, nil
 }
 
-func commandMap(config *config, cache *pokecache.Cache, userParam string) error {
-	var jsonData locationAreaAPIResponse
-	//fmt.Print(config.previous)
-	var data []byte
+func commandMap(config *config, args commandArgs) (any, error) {
+	var pageURL string
 	if config.previous == "" {
-		//	fmt.Print("EMPTY PREVIOUS LOL")
-		if cacheData, ok := cache.Get("https://pokeapi.co/api/v2/location-area/"); ok {
-			//fmt.Println("using cache")
-			data = cacheData
-		} else {
-			res, err := http.Get("https://pokeapi.co/api/v2/location-area/")
-			//fmt.Println("fetching data")
-			if err != nil {
-				return err
-			}
-			data, err = io.ReadAll(res.Body)
-			if err != nil {
-				return err
-			}
-			cache.Add("https://pokeapi.co/api/v2/location-area/", data)
-		}
-		err := json.Unmarshal(data, &jsonData)
-		if err != nil {
-			return err
-		}
-
-		config.previous = "https://pokeapi.co/api/v2/location-area/"
-		config.next = jsonData.Next
+		pageURL = config.client.LocationAreaURL("")
 	} else {
 		if config.next == "" {
-			return fmt.Errorf("either there are no locations left, or an error occured")
-		}
-		if cacheData, ok := cache.Get(config.next); ok {
-			//fmt.Println("using cache")
-			data = cacheData
-		} else {
-			res, err := http.Get(config.next)
-			//fmt.Println("fetching data")
-			if err != nil {
-				return err
-			}
-			data, err = io.ReadAll(res.Body)
-			if err != nil {
-				return err
-			}
-			cache.Add(config.next, data)
-		}
-		err := json.Unmarshal(data, &jsonData)
-		if err != nil {
-			return err
+			return nil, fmt.Errorf("either there are no locations left, or an error occured")
 		}
-
-		config.previous = config.next
-		config.next = jsonData.Next
+		pageURL = config.next
 	}
-	for _, location := range jsonData.Results {
-		fmt.Println(location.Name)
+	jsonData, err := config.client.ListLocationAreas(pageURL)
+	if err != nil {
+		return nil, friendlyError(err, "such location page")
 	}
-	//var data string
-	//decoder := json.NewDecoder(res.Body)
-	//err = decoder.Decode(&data)
-	//fmt.Println(jsonData["previous"])
-	//fmt.Println(jsonData["next"])
-
-	fmt.Println(jsonData.Previous)
-	fmt.Println(jsonData.Next)
-	return nil
+	config.previous = pageURL
+	config.next = jsonData.Next
+	page := newLocationAreaPage(jsonData)
+	rememberAreas(config, page)
+	return page, nil
 }
 
-func commandMapb(config *config, cache *pokecache.Cache, userParam string) error {
-	var jsonData locationAreaAPIResponse
-	//fmt.Print(config.previous)
-	var data []byte
+func commandMapb(config *config, args commandArgs) (any, error) {
 	if config.previous == "" {
-		fmt.Println("you're on the first page")
-		return nil
-	} else {
-		if cacheData, ok := cache.Get(config.previous); ok {
-			//fmt.Println("using cache")
-			data = cacheData
-		} else {
-			//fmt.Println("fetching data")
-			res, err := http.Get(config.previous)
-			if err != nil {
-				return err
-			}
-			data, err = io.ReadAll(res.Body)
-			if err != nil {
-				return err
-			}
-			cache.Add(config.previous, data)
-		}
-		err := json.Unmarshal(data, &jsonData)
-		if err != nil {
-			return err
-		}
-		config.next = config.previous
-		config.previous = jsonData.Previous
+		return messageResult{Message: "you're on the first page"}, nil
+	}
+	jsonData, err := config.client.ListLocationAreas(config.previous)
+	if err != nil {
+		return nil, friendlyError(err, "such location page")
+	}
+	config.next = config.previous
+	config.previous = jsonData.Previous
+	page := newLocationAreaPage(jsonData)
+	rememberAreas(config, page)
+	return page, nil
+}
 
+func newLocationAreaPage(jsonData pokeapi.LocationAreaAPIResponse) locationAreaPage {
+	page := locationAreaPage{
+		Areas:    []string{},
+		Previous: jsonData.Previous,
+		Next:     jsonData.Next,
 	}
 	for _, location := range jsonData.Results {
-		fmt.Println(location.Name)
+		page.Areas = append(page.Areas, location.Name)
 	}
-	fmt.Println(jsonData.Previous)
-	fmt.Println(jsonData.Next)
-	return nil
+	return page
 }
 
-func commandExplore(config *config, cache *pokecache.Cache, location string) error {
-	var jsonData locationAPIResponse
-	var data []byte
-	if location == "" {
-		fmt.Println("Please enter a location")
-		return fmt.Errorf("no location parameter")
-	}
-	url := "https://pokeapi.co/api/v2/location-area/" + location
-	config.area = location
-	if cacheData, ok := cache.Get(url); ok {
-		//fmt.Println("using cache")
-		data = cacheData
-	} else {
-		//fmt.Println("fetching data")
-		res, err := http.Get(url)
-		if err != nil {
-			return err
-		}
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return err
+// rememberAreas records the areas of a map page for completion.
+func rememberAreas(config *config, page locationAreaPage) {
+	if config.seenAreas == nil {
+		config.seenAreas = map[string]bool{}
+	}
+	for _, area := range page.Areas {
+		config.seenAreas[area] = true
+	}
+}
+
+func commandExplore(config *config, args commandArgs) (any, error) {
+	location, err := resolveName(config, "location-area", "location area", strings.Join(args.positional, " "))
+	if err != nil {
+		return nil, err
+	}
+	version := config.game
+	if v, ok := args.flag("version"); ok {
+		if version, err = resolveName(config, "version", "game version", v); err != nil {
+			return nil, err
 		}
-		cache.Add(config.previous, data)
 	}
-	err := json.Unmarshal(data, &jsonData)
+	jsonData, err := config.client.GetLocationArea(location)
 	if err != nil {
-		return err
+		return nil, friendlyError(err, "location area called "+location)
+	}
+	config.area = location
+	config.location = jsonData
+	if config.prefetcher != nil {
+		names := make([]string, 0, len(jsonData.PokemonEncounters))
+		for _, occurrence := range jsonData.PokemonEncounters {
+			names = append(names, occurrence.Pokemon.Name)
+		}
+		config.prefetcher.Start(names)
 	}
+	res := exploreResult{Area: location, Version: version, Pokemon: []string{}, Encounters: []exploreEncounter{}}
 	for _, occurrence := range jsonData.PokemonEncounters {
-		fmt.Println(occurrence.Pokemon.Name)
+		versions := versionEncounters(occurrence.VersionDetails, version)
+		if version != "" && len(versions) == 0 {
+			continue
+		}
+		res.Pokemon = append(res.Pokemon, occurrence.Pokemon.Name)
+		res.Encounters = append(res.Encounters, exploreEncounter{Pokemon: occurrence.Pokemon.Name, Versions: versions})
 	}
-	return nil
+	return res, nil
 }
 
-func commandCatch(config *config, cache *pokecache.Cache, pokemon string) error {
-	var jsonData pokemonAPIResponse
-	var data []byte
-	/*
-		if config.area == "" {
-			fmt.Println("Please explore an area before trying to catch a pokemon")
-			return nil
-		}
-	*/
-	url := "https://pokeapi.co/api/v2/pokemon/" + pokemon
-	if cacheData, ok := cache.Get(url); ok {
-		//fmt.Println("using cache")
-		data = cacheData
-	} else {
-		//fmt.Println("fetching data")
-		res, err := http.Get(url)
-		if err != nil {
-			return err
-		}
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return err
+func commandCatch(config *config, args commandArgs) (any, error) {
+	ballName, _ := args.flag("ball")
+	ball, err := parseBall(ballName)
+	if err != nil {
+		return nil, err
+	}
+	var pokemon string
+	if input := strings.Join(args.positional, " "); input != "" {
+		switch {
+		case config.sandbox:
+			if pokemon, err = resolveName(config, "pokemon", "pokemon", input); err != nil {
+				return nil, err
+			}
+		case config.area != "":
+			// only the pokemon met here can be caught, so correct typos against those
+			name := normalizeName(input)
+			match, suggestions, ok := matchName("pokemon", name, areaPokemon(config))
+			if !ok {
+				return nil, fmt.Errorf("There is no %s in %s%s", name, encounterPlace(config), didYouMean(suggestions))
+			}
+			pokemon = match
+		default:
+			// findWild asks to explore first
+			pokemon = normalizeName(input)
 		}
-		cache.Add(config.previous, data)
 	}
-	err := json.Unmarshal(data, &jsonData)
+	catchBonus := 1.0
+	var wild wildEncounter
+	if b := config.battle; b != nil && (pokemon == "" || pokemon == b.Wild.Name) {
+		wild = wildEncounter{name: b.Wild.Name, level: b.Wild.Level}
+		catchBonus = b.CatchBonus()
+	} else if wild, err = findWild(config, pokemon); err != nil {
+		return nil, err
+	}
+	pokemon = wild.name
+	jsonData, err := config.client.GetPokemon(pokemon)
 	if err != nil {
-		return err
+		return nil, friendlyError(err, "pokemon called "+pokemon)
 	}
 	//suppose max diff is 400
 	// observed min diff being 40
 	//base chance should be about 35%
 	//min chance should be about 1%
-	fmt.Print("Throwing a Pokeball at ", pokemon, "...\n")
-	pokemonCatchDifficulty := (400 - jsonData.BaseExperience) / 10
-	fmt.Println(pokemonCatchDifficulty)
+	pokemonCatchDifficulty := int(float64((400-jsonData.BaseExperience)/10) * ball.multiplier * catchBonus)
 	pokemonCatched := rand.Intn(100) - pokemonCatchDifficulty
-	fmt.Println(pokemonCatched)
-	if pokemonCatched < 0 {
-		fmt.Println(pokemon, "was caught!")
-		config.pokedex[pokemon] = jsonData
-	} else {
-		fmt.Println(pokemon, "escaped!")
+	res := catchResult{
+		Pokemon:   pokemon,
+		Level:     wild.level,
+		Ball:      ball.name,
+		CatchRate: pokemonCatchDifficulty,
+		Roll:      pokemonCatched,
+		Caught:    ball.alwaysCatches || pokemonCatched < 0,
 	}
-	return nil
-}
-
-func commandInspect(config *config, cache *pokecache.Cache, pokemon string) error {
-	if pokemon == "" {
-		fmt.Println("You have not caught that pokemon")
-	}
-	if value, exists := config.pokedex[pokemon]; exists {
-		fmt.Println("Name:", value.Name)
-		fmt.Println("Height:", value.Height)
-		fmt.Println("Weight:", value.Weight)
-		fmt.Println("Stats:")
-		for _, val := range value.Stats {
-			fmt.Print("	-", val.Stat.Name, ":", val.BaseStat, "\n")
+	if res.Caught {
+		if config.battle != nil && config.battle.Wild.Name == pokemon {
+			config.battle = nil
 		}
-		fmt.Println("Types:")
-		for _, val := range value.Types {
-			fmt.Print("	-", val.Type.Name, "\n")
+		config.pokedex[pokemon] = caughtPokemon{
+			Pokemon:  jsonData,
+			Level:    wild.level,
+			CaughtAt: time.Now(),
+			Area:     config.area,
 		}
-	} else {
-		fmt.Println("You have not caught that pokemon")
+		autosave(config)
 	}
-	return nil
+	return res, nil
 }
 
-func commandPokedex(config *config, cache *pokecache.Cache, pokemon string) error {
-	fmt.Println("Your pokedex:")
+func commandInspect(config *config, args commandArgs) (any, error) {
+	pokemon := strings.ToLower(args.arg(0))
+	caught, exists := config.pokedex[pokemon]
+	if !exists {
+		return nil, errors.New("You have not caught that pokemon")
+	}
+	details := newPokemonDetails(caught)
+	weaknesses, err := weaknessesOf(config, details.Types)
+	if err != nil {
+		// the rest of the entry is in the pokedex, so still show it
+		fmt.Fprintln(os.Stderr, "could not work out the weaknesses:", err)
+	}
+	details.Weaknesses = weaknesses
+	art, err := spriteArt(config, args, caught.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+	details.Sprite = art
+	return details, nil
+}
+
+func commandPokedex(config *config, args commandArgs) (any, error) {
+	query, err := dexquery.Parse(strings.Join(args.positional, " "))
+	if err != nil {
+		return nil, err
+	}
+	subjects := make([]dexquery.Subject, 0, len(config.pokedex))
 	for _, v := range config.pokedex {
-		fmt.Println("-", v.Name)
+		subjects = append(subjects, dexquery.Subject{Pokemon: v.Pokemon, Level: v.Level, Area: v.Area})
+	}
+	res := pokedexResult{Pokemon: []pokedexEntry{}}
+	for _, subject := range query.Apply(subjects) {
+		v := config.pokedex[subject.Pokemon.Name]
+		res.Pokemon = append(res.Pokemon, pokedexEntry{
+			Name:     v.Pokemon.Name,
+			Level:    v.Level,
+			Area:     v.Area,
+			CaughtAt: v.CaughtAt,
+		})
+	}
+	return res, nil
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
 	}
-	return nil
+	return filepath.Join(dir, "pokedexcli")
 }
 
 func main() {
+	apiURL := flag.String("api-url", pokeapi.DefaultBaseURL, "base URL of the PokeAPI to query")
+	cacheDir := flag.String("cache-dir", defaultCacheDir(), "directory for the on-disk response cache, empty to disable it")
+	diskCacheTTL := flag.Duration("disk-cache-ttl", 7*24*time.Hour, "how long on-disk responses stay valid before they are revalidated")
+	diskCacheMaxMB := flag.Int64("disk-cache-max-mb", 200, "maximum size of the on-disk cache in megabytes")
+	cacheMaxMB := flag.Int64("cache-max-mb", 64, "maximum size of the in-memory cache in megabytes, 0 for no limit")
+	cacheMaxEntries := flag.Int("cache-max-entries", 0, "maximum number of responses kept in memory, 0 for no limit")
+	prefetchPokemon := flag.Bool("prefetch", false, "fetch the pokemon of an explored area in the background")
+	prefetchWorkers := flag.Int("prefetch-workers", 4, "number of concurrent background fetches")
+	prefetchRate := flag.Float64("prefetch-rate", 5, "maximum background requests per second, 0 for no limit")
+	sandbox := flag.Bool("sandbox", false, "allow catching any pokemon, wherever you explored")
+	httpTimeout := flag.Duration("http-timeout", 10*time.Second, "timeout of each PokeAPI request attempt")
+	maxRetries := flag.Int("max-retries", pokeapi.DefaultMaxRetries, "retries of a PokeAPI request on 429 and 5xx responses, 0 for none")
+	rateLimit := flag.Float64("rate-limit", 20, "maximum PokeAPI requests per second, 0 for no limit")
+	offline := flag.Bool("offline", false, "read PokeAPI data from the mirror in -offline-dir instead of the network")
+	offlineDir := flag.String("offline-dir", filepath.Join(dataDir(), "api-data"), "directory of a PokeAPI api-data mirror, used with -offline")
+	historyPath := flag.String("history-file", filepath.Join(dataDir(), "history"), "file REPL history is kept in, empty to disable it")
+	savePath := flag.String("save-file", defaultSavePath(), "file the pokedex is saved to and loaded from")
+	output := flag.String("output", string(render.Text), "output format: text, json, yaml or table")
+	commandLine := flag.String("c", "", "run the given ';'-separated commands and exit")
+	scriptPath := flag.String("f", "", "run the commands in the given file and exit")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: pokedexcli [flags] [command [args...]]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	var cfgCmd config
-	cfgCmd.pokedex = map[string]pokemonAPIResponse{}
-	cache := pokecache.NewCache(30 * time.Second)
+	format, err := render.ParseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	cfgCmd.output = format
+	cfgCmd.savePath = *savePath
+	cfgCmd.sandbox = *sandbox
+	cfgCmd.offline = *offline
+	cfgCmd.offlineDir = *offlineDir
+	if err := openPokedex(&cfgCmd); err != nil {
+		fmt.Fprintln(os.Stderr, "could not load the pokedex:", err)
+		fmt.Fprintln(os.Stderr, "it will not be saved until you run save")
+	}
+	if *historyPath != "" {
+		cfgCmd.history = lineedit.NewHistory(historySize)
+		if err := cfgCmd.history.Load(*historyPath); err != nil {
+			fmt.Fprintln(os.Stderr, "could not load the history:", err)
+		}
+	}
+	cacheOpts := []pokecache.Option{
+		pokecache.WithMaxBytes(*cacheMaxMB << 20),
+		pokecache.WithMaxEntries(*cacheMaxEntries),
+	}
+	// the mirror is already on disk, caching it there again would be wasted
+	if *cacheDir != "" && !*offline {
+		disk, err := pokecache.NewDiskStore(*cacheDir, *diskCacheTTL, *diskCacheMaxMB<<20)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "disk cache disabled:", err)
+		} else {
+			cacheOpts = append(cacheOpts, pokecache.WithDiskStore(disk))
+		}
+	}
+	cache := pokecache.NewCache(30*time.Second, cacheOpts...)
+	cfgCmd.onShutdown(cache.Close)
+	cfgCmd.cache = cache
+	if *offline {
+		source, err := pokeapi.NewDirSource(*offlineDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		cfgCmd.client = pokeapi.NewClientWithSource(pokeapi.OfflineBaseURL, source, cache)
+	} else {
+		httpClient := &http.Client{Transport: pokeapi.NewTransport(nil, pokeapi.TransportOptions{
+			Timeout:           *httpTimeout,
+			MaxRetries:        *maxRetries,
+			RequestsPerSecond: *rateLimit,
+			Burst:             5,
+		})}
+		cfgCmd.client = pokeapi.NewClient(*apiURL, httpClient, cache)
+	}
+	cfgCmd.typeChart = typechart.New(cfgCmd.client)
+	if *prefetchPokemon {
+		cfgCmd.prefetcher = prefetch.New(*prefetchWorkers, *prefetchRate, cfgCmd.client.PrefetchPokemon)
+		cfgCmd.onShutdown(cfgCmd.prefetcher.Stop)
+	}
 	cmdRegistry = map[string]cliCommand{
 		"help": {
 			name:        "help",
+			usage:       "help [command]",
+			maxArgs:     1,
 			description: "Displays a help message",
 			callback:    commandHelp,
 		},
 		"map": {
 			name:        "map",
+			usage:       "map",
+			maxArgs:     0,
 			description: "Lists 20 Poke-Locations... and the next 20 ones for each subsequent commands",
 			callback:    commandMap,
 		},
 		"mapb": {
 			name:        "mapb",
+			usage:       "mapb",
+			maxArgs:     0,
 			description: "Lists previous map results, if exist",
 			callback:    commandMapb,
 		},
 		"explore": {
-			name:        "explore",
-			description: "Allows the user to see existing pokemon at a given location eg. 'explore location-name' as listed with map command",
+			name:    "explore",
+			usage:   "explore <location-area> [--version=<game>]",
+			minArgs: 1,
+			maxArgs: -1,
+			flags: map[string]string{
+				"version": "only list pokemon encountered in this game version, overriding the game command",
+			},
+			description: "Allows the user to see existing pokemon at a given location eg. 'explore location-name' as listed with map command, with their chance and levels in each game",
 			callback:    commandExplore,
 		},
 		"catch": {
-			name:        "catch",
-			description: "Tries to catch a pokemon existing in an area",
-			callback:    commandCatch,
+			name:    "catch",
+			usage:   "catch [pokemon] [--ball=poke|great|ultra|master]",
+			maxArgs: -1,
+			flags: map[string]string{
+				"ball": "the ball to throw, defaults to poke",
+			},
+			description:  "Tries to catch a pokemon encountered in the explored area and game, or a random one from it if none is given",
+			changesState: true,
+			callback:     commandCatch,
+		},
+		"game": {
+			name:        "game",
+			usage:       "game [version|all]",
+			maxArgs:     -1,
+			description: "Shows or sets the game version, e.g. diamond, whose encounters explore, catch and battle use; all goes back to every version",
+			callback:    commandGame,
+		},
+		"battle": {
+			name:        "battle",
+			usage:       "battle <pokemon> [wild-pokemon]",
+			minArgs:     1,
+			maxArgs:     2,
+			description: "Sends a caught pokemon to fight a wild pokemon of the explored area, weakening it makes it easier to catch",
+			callback:    commandBattle,
+		},
+		"attack": {
+			name:        "attack",
+			usage:       "attack [move]",
+			maxArgs:     1,
+			description: "Plays a battle turn with the given move, or the first one your pokemon knows",
+			callback:    commandAttack,
+		},
+		"run": {
+			name:         "run",
+			usage:        "run",
+			maxArgs:      0,
+			description:  "Flees the current battle",
+			changesState: true,
+			callback:     commandRun,
+		},
+		"matchup": {
+			name:        "matchup",
+			usage:       "matchup <attacker> <defender>",
+			minArgs:     2,
+			maxArgs:     2,
+			description: "Shows how effective the types of a pokemon (or a type) are against another",
+			callback:    commandMatchup,
+		},
+		"weakness": {
+			name:        "weakness",
+			usage:       "weakness <pokemon>",
+			minArgs:     1,
+			maxArgs:     1,
+			description: "Lists the types a pokemon (or a type) is weak to, resists and is immune to",
+			callback:    commandWeakness,
+		},
+		"evolution": {
+			name:        "evolution",
+			usage:       "evolution <pokemon>",
+			minArgs:     1,
+			maxArgs:     1,
+			description: "Shows the evolution chain of a pokemon and what triggers each evolution",
+			callback:    commandEvolution,
+		},
+		"evolve": {
+			name:         "evolve",
+			usage:        "evolve <pokemon>",
+			minArgs:      1,
+			maxArgs:      1,
+			description:  "Evolves a caught pokemon whose level is high enough",
+			changesState: true,
+			callback:     commandEvolve,
 		},
 		"inspect": {
-			name:        "inspect",
-			description: "If already caught, displays info about a given pokemon",
+			name:    "inspect",
+			usage:   "inspect <pokemon> [--sprite=<version>] [--art=truecolor|256|ascii|none]",
+			minArgs: 1,
+			maxArgs: 1,
+			flags: map[string]string{
+				"sprite": "sprite to draw: default, shiny, a game like red-blue or a generation like generation-i",
+				"art":    "how to draw the sprite, detected from the terminal by default",
+			},
+			description: "If already caught, displays info about a given pokemon, with its sprite on a terminal",
 			callback:    commandInspect,
 		},
 		"pokedex": {
 			name:        "pokedex",
-			description: "Displays a list of pokemon in the pokedex",
+			usage:       "pokedex [query]",
+			maxArgs:     -1,
+			description: "Displays the pokemon in the pokedex, filtered and sorted by a query such as 'type:water stat.speed>80 sort:-base_experience limit:10'. Fields are name, type, ability, height, weight, base_experience, level, area and stat.<name>",
 			callback:    commandPokedex,
 		},
+		"save": {
+			name:         "save",
+			usage:        "save [path]",
+			maxArgs:      1,
+			description:  "Saves the pokedex, to the save file or to a given path",
+			changesState: true,
+			callback:     commandSave,
+		},
+		"load": {
+			name:         "load",
+			usage:        "load [path]",
+			maxArgs:      1,
+			description:  "Loads the pokedex, from the save file or from a given path (which turns autosave off until the next save)",
+			changesState: true,
+			callback:     commandLoad,
+		},
+		"cache": {
+			name:         "cache",
+			usage:        "cache stats|list|clear|purge <url-prefix>",
+			minArgs:      1,
+			maxArgs:      2,
+			description:  "Shows cache statistics and entries, or removes cached responses",
+			changesState: true,
+			callback:     commandCache,
+		},
+		"sync": {
+			name:    "sync",
+			usage:   "sync [--dir=<dir>] [--workers=<n>]",
+			maxArgs: 0,
+			flags: map[string]string{
+				"dir":     "mirror directory, defaults to -offline-dir",
+				"workers": "number of concurrent downloads, defaults to 4",
+			},
+			description:  "Downloads location areas and their pokemon, species, evolutions, moves and types, plus the game versions, for use with -offline, resuming an interrupted sync",
+			changesState: true,
+			callback:     commandSync,
+		},
+		"history": {
+			name:         "history",
+			usage:        "history [number]",
+			maxArgs:      1,
+			description:  "Lists the commands entered in past and current sessions, or runs the one with the given number again",
+			changesState: true,
+			callback:     commandHistory,
+		},
 		"exit": {
-			name:        "exit",
-			description: "Exit the Pokedex",
-			callback:    commandExit,
+			name:         "exit",
+			usage:        "exit",
+			maxArgs:      0,
+			description:  "Exit the Pokedex",
+			changesState: true,
+			callback:     commandExit,
 		},
 	}
-	scanner := bufio.NewScanner(os.Stdin)
-	for {
-		fmt.Print("Pokedex >")
-		if !scanner.Scan() {
-			fmt.Println("No more input. Exiting.")
-			break
+	handleSignals(&cfgCmd)
+	switch {
+	case *commandLine != "":
+		err = cfgCmd.whileBusy(func() error { return runLine(&cfgCmd, *commandLine) })
+	case *scriptPath != "":
+		var script *os.File
+		script, err = os.Open(*scriptPath)
+		if err == nil {
+			err = runScript(&cfgCmd, script)
+			script.Close()
 		}
-		userCurrentInput := scanner.Text()
-		loweredUserInput := strings.ToLower(userCurrentInput)
-		userWords := strings.Fields(loweredUserInput)
-		if len(userWords) == 0 {
-			fmt.Println("Please enter a valid command.")
-			continue
+	case flag.NArg() > 0:
+		err = cfgCmd.whileBusy(func() error { return runTokens(&cfgCmd, flag.Args()) })
+	case !isTerminal(os.Stdin):
+		err = runScript(&cfgCmd, os.Stdin)
+	default:
+		cfgCmd.interactive = true
+		editor := lineedit.New(os.Stdin, os.Stdout, func(head string) (int, []string) {
+			return completeLine(&cfgCmd, head)
+		})
+		// a signal can end the program while the terminal is in raw mode
+		cfgCmd.onShutdown(editor.Restore)
+		if cfgCmd.history != nil {
+			editor.SetHistory(cfgCmd.history)
+			cfgCmd.onShutdown(func() error {
+				return cfgCmd.history.Save(*historyPath)
+			})
 		}
-		userCommand := userWords[0]
-		cmdNotFound := true
-		var userFirstParameter string
-		for registryItem := range cmdRegistry {
-			if userCommand == registryItem {
-				cmdNotFound = false
-				if len(userWords) >= 2 {
-					userFirstParameter = userWords[1]
-				}
-				cmdRegistry[userCommand].callback(&cfgCmd, cache, userFirstParameter)
-			}
-		}
-		if cmdNotFound {
-			fmt.Print("Unknown command\n")
-		}
-		//fmt.Print("Your command was: ", userWords[0], "\n")
+		repl(&cfgCmd, editor)
+	}
+	shutdown(&cfgCmd)
+	if err != nil && !errors.Is(err, errExit) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/fuzzy"
+)
+
+// normalizeName turns what the user typed into PokeAPI's name style, so
+// "Canalave City" becomes "canalave-city".
+func normalizeName(input string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '\t'
+	}), "-")
+}
+
+// resourceNames returns every name of a kind of resource, fetched once per
+// session.
+func resourceNames(config *config, resource string) ([]string, error) {
+	if names, ok := config.nameIndex[resource]; ok {
+		return names, nil
+	}
+	names, err := config.client.ListNames(resource)
+	if err != nil {
+		return nil, err
+	}
+	if config.nameIndex == nil {
+		config.nameIndex = map[string][]string{}
+	}
+	config.nameIndex[resource] = names
+	return names, nil
+}
+
+// resolveName finds the resource meant by input, correcting typos against
+// the list of every resource of that kind. what names the kind in messages,
+// e.g. "location area".
+func resolveName(config *config, resource, what, input string) (string, error) {
+	name := normalizeName(input)
+	names, err := resourceNames(config, resource)
+	if err != nil {
+		return "", friendlyError(err, "list of "+what+"s")
+	}
+	match, suggestions, ok := matchName(what, name, names)
+	if !ok {
+		return "", fmt.Errorf("There is no %s called %s%s", what, name, didYouMean(suggestions))
+	}
+	return match, nil
+}
+
+// matchName corrects a typo in name against names, telling the user when it
+// does. Otherwise it returns the closest names to suggest.
+func matchName(what, name string, names []string) (string, []string, bool) {
+	match, suggestions, ok := fuzzy.Resolve(name, names)
+	if ok && match != name {
+		fmt.Fprintf(os.Stderr, "Assuming %s %s\n", what, match)
+	}
+	return match, suggestions, ok
+}
+
+// resolveCommand finds the command meant by name. Typos are only corrected
+// in the REPL, where the guess is shown before anything else happens, and
+// never into commands that change state: those are suggested instead, as
+// running the wrong one can't be taken back. Scripts fail on any typo.
+func resolveCommand(config *config, name string) (cliCommand, error) {
+	if cmd, ok := cmdRegistry[name]; ok {
+		return cmd, nil
+	}
+	match, suggestions, ok := fuzzy.Resolve(name, sortedKeys(cmdRegistry))
+	if ok && (!config.interactive || cmdRegistry[match].changesState) {
+		ok, suggestions = false, []string{match}
+	}
+	if !ok {
+		return cliCommand{}, fmt.Errorf("Unknown command %q%s", name, didYouMean(suggestions))
+	}
+	fmt.Fprintf(os.Stderr, "Assuming %s\n", match)
+	return cmdRegistry[match], nil
+}
+
+// didYouMean phrases suggestions to append to an error message.
+func didYouMean(suggestions []string) string {
+	switch len(suggestions) {
+	case 0:
+		return ""
+	case 1:
+		return ", did you mean " + suggestions[0] + "?"
+	default:
+		last := len(suggestions) - 1
+		return ", did you mean " + strings.Join(suggestions[:last], ", ") + " or " + suggestions[last] + "?"
+	}
+}
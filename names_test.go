@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"Canalave City":     "canalave-city",
+		"  mr_mime ":        "mr-mime",
+		"eterna-city--area": "eterna-city-area",
+		"pikachu":           "pikachu",
+	}
+	for input, want := range cases {
+		if got := normalizeName(input); got != want {
+			t.Errorf("normalizeName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestResolveCommand(t *testing.T) {
+	saved := cmdRegistry
+	t.Cleanup(func() { cmdRegistry = saved })
+	cmdRegistry = map[string]cliCommand{
+		"explore": {name: "explore"},
+		"map":     {name: "map"},
+		"mapb":    {name: "mapb"},
+		"load":    {name: "load", changesState: true},
+	}
+	repl := &config{interactive: true}
+
+	if cmd, err := resolveCommand(repl, "exlpore"); err != nil || cmd.name != "explore" {
+		t.Errorf("expected exlpore to resolve to explore, got %q, %v", cmd.name, err)
+	}
+	_, err := resolveCommand(&config{}, "exlpore")
+	if err == nil || err.Error() != `Unknown command "exlpore", did you mean explore?` {
+		t.Errorf("expected scripts not to correct typos, got %v", err)
+	}
+	_, err = resolveCommand(repl, "lod")
+	if err == nil || err.Error() != `Unknown command "lod", did you mean load?` {
+		t.Errorf("expected no correction into load, got %v", err)
+	}
+	_, err = resolveCommand(repl, "ma")
+	if err == nil || err.Error() != `Unknown command "ma", did you mean map or mapb?` {
+		t.Errorf("unexpected error %v", err)
+	}
+	_, err = resolveCommand(repl, "xyz")
+	if err == nil || err.Error() != `Unknown command "xyz"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestResolveNameReportsMissingIndex(t *testing.T) {
+	config := &config{client: pokeapi.NewClientWithSource("/api/v2", mapSource{}, nil)}
+	if _, err := resolveName(config, "pokemon", "pokemon", "pikachu"); err == nil {
+		t.Errorf("expected the failed name list to be reported")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/lineedit"
+	"github.com/tholho/pokedexcli/internal/render"
+)
+
+var (
+	errEmptyCommand = errors.New("empty command")
+	// errExit is returned by the exit command to stop whatever loop runs it.
+	errExit = errors.New("exit requested")
+)
+
+// runCommand parses one line of input and dispatches it to the registry.
+func runCommand(config *config, line string) error {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return err
+	}
+	return runTokens(config, tokens)
+}
+
+func runTokens(config *config, tokens []string) error {
+	if len(tokens) == 0 {
+		return errEmptyCommand
+	}
+	cmd, err := resolveCommand(config, strings.ToLower(tokens[0]))
+	if err != nil {
+		return err
+	}
+	args := parseArgs(tokens[1:])
+	format := config.output
+	if name, ok := args.flag("output"); ok {
+		var err error
+		if format, err = render.ParseFormat(name); err != nil {
+			return err
+		}
+	}
+	if _, ok := args.flag("help"); ok {
+		return render.Render(os.Stdout, format, cmd.help())
+	}
+	if err := cmd.validate(args); err != nil {
+		return err
+	}
+	res, err := cmd.callback(config, args)
+	if err != nil {
+		return err
+	}
+	return render.Render(os.Stdout, format, res)
+}
+
+// runLine runs the ';'-separated commands of a line, stopping at the first
+// error. Empty statements are skipped.
+func runLine(config *config, line string) error {
+	for _, statement := range splitStatements(line) {
+		err := runCommand(config, statement)
+		if errors.Is(err, errEmptyCommand) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runScript runs every line of r as with runLine. Lines starting with '#'
+// are comments.
+func runScript(config *config, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := config.whileBusy(func() error { return runLine(config, line) }); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// repl prompts for commands until exit or end of input. Errors are printed
+// and the loop carries on; Ctrl-C drops the line being typed.
+func repl(config *config, editor *lineedit.Editor) {
+	for {
+		line, err := editor.ReadLine("Pokedex >")
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			continue
+		}
+		if err != nil {
+			fmt.Println("No more input. Exiting.")
+			return
+		}
+		err = config.whileBusy(func() error {
+			if config.history != nil {
+				config.history.Add(line)
+			}
+			return runCommand(config, line)
+		})
+		if errors.Is(err, errExit) {
+			return
+		} else if errors.Is(err, errEmptyCommand) {
+			fmt.Println("Please enter a valid command.")
+		} else if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -61,3 +68,304 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestStatsCountStaleEntriesApart(t *testing.T) {
+	cache := pokecache.NewCache(5 * time.Millisecond)
+	defer cache.Close()
+	cache.AddValidated("https://example.com/a", []byte("a"), pokecache.Validators{ETag: `"a"`})
+	cache.Add("https://example.com/b", []byte("b"))
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().Stale == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Stale != 1 {
+		t.Errorf("expected only 1 stale entry, got %+v", stats)
+	}
+	if entries := cache.Entries(); len(entries) != 0 {
+		t.Errorf("expected no listed entries, got %+v", entries)
+	}
+}
+
+func TestDiskStoreSurvivesNewCache(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := pokecache.NewDiskStore(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pokecache.NewCache(time.Minute, pokecache.WithDiskStore(disk)).Add("https://example.com", []byte("testdata"))
+
+	disk, err = pokecache.NewDiskStore(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	val, ok := pokecache.NewCache(time.Minute, pokecache.WithDiskStore(disk)).Get("https://example.com")
+	if !ok {
+		t.Errorf("expected to find key on disk")
+		return
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected to find value")
+	}
+}
+
+func TestDiskStoreMaxBytes(t *testing.T) {
+	disk, err := pokecache.NewDiskStore(t.TempDir(), time.Hour, 300)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.Add("https://example.com/old", make([]byte, 100), pokecache.Validators{})
+	time.Sleep(10 * time.Millisecond)
+	disk.Add("https://example.com/new", make([]byte, 100), pokecache.Validators{})
+
+	if _, _, ok := disk.Get("https://example.com/old"); ok {
+		t.Errorf("expected oldest entry to be evicted")
+	}
+	if _, _, ok := disk.Get("https://example.com/new"); !ok {
+		t.Errorf("expected newest entry to be kept")
+	}
+}
+
+func TestDiskStoreTrimKeepsRevalidatable(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := pokecache.NewDiskStore(dir, 10*time.Millisecond, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.Add("https://example.com/etag", []byte("a"), pokecache.Validators{ETag: `"v1"`})
+	disk.Add("https://example.com/plain", []byte("b"), pokecache.Validators{})
+	time.Sleep(20 * time.Millisecond)
+	// adding trims the expired entries
+	disk.Add("https://example.com/new", []byte("c"), pokecache.Validators{})
+
+	if files, _ := os.ReadDir(dir); len(files) != 2 {
+		t.Errorf("expected the expired entry without validators to be removed, got %d files", len(files))
+	}
+	if val, validators, ok := disk.Stale("https://example.com/etag"); !ok || string(val) != "a" || validators.ETag != `"v1"` {
+		t.Errorf("expected the entry with validators to be kept, got %q, %+v", val, validators)
+	}
+	// re-adding without validators replaces the revalidatable copy
+	disk.Add("https://example.com/etag", []byte("d"), pokecache.Validators{})
+	if val, validators, ok := disk.Get("https://example.com/etag"); !ok || string(val) != "d" || !validators.IsZero() {
+		t.Errorf("expected the new copy, got %q, %+v", val, validators)
+	}
+}
+
+func TestLRUMaxEntries(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute, pokecache.WithMaxEntries(2))
+	cache.Add("https://example.com/a", []byte("a"))
+	cache.Add("https://example.com/b", []byte("b"))
+	// touch a so that b becomes the least recently used entry
+	cache.Get("https://example.com/a")
+	cache.Add("https://example.com/c", []byte("c"))
+
+	if _, ok := cache.Get("https://example.com/b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	for _, key := range []string{"https://example.com/a", "https://example.com/c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected to find %s", key)
+		}
+	}
+}
+
+func TestLRUMaxBytes(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute, pokecache.WithMaxBytes(10))
+	cache.Add("https://example.com/a", []byte("aaaa"))
+	cache.Add("https://example.com/b", []byte("bbbb"))
+	cache.Add("https://example.com/c", []byte("cccc"))
+
+	if _, ok := cache.Get("https://example.com/a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+	for _, key := range []string{"https://example.com/b", "https://example.com/c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected to find %s", key)
+		}
+	}
+
+	// a single value above the budget is still kept until something replaces it
+	cache.Add("https://example.com/big", []byte("0123456789abcdef"))
+	if _, ok := cache.Get("https://example.com/big"); !ok {
+		t.Errorf("expected the newest entry to be kept")
+	}
+	if _, ok := cache.Get("https://example.com/c"); ok {
+		t.Errorf("expected c to be evicted")
+	}
+}
+
+func TestCloseStopsReaper(t *testing.T) {
+	before := runtime.NumGoroutine()
+	caches := make([]*pokecache.Cache, 10)
+	for i := range caches {
+		caches[i] = pokecache.NewCache(time.Millisecond)
+	}
+	if runtime.NumGoroutine() < before+len(caches) {
+		t.Fatalf("expected one reaper goroutine per cache")
+	}
+	for _, cache := range caches {
+		cache.Close()
+	}
+	// closing twice must not block or panic
+	caches[0].Close()
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected %d goroutines after Close, got %d", before, after)
+	}
+}
+
+func TestContextStopsReaper(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := pokecache.NewCache(time.Millisecond, pokecache.WithContext(ctx))
+	defer cache.Close()
+	if runtime.NumGoroutine() <= before {
+		t.Fatalf("expected the cache to start a reaper goroutine")
+	}
+	cancel()
+	// Close would stop the reaper by itself, so only cancel may run here
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d goroutines after cancel, got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDiskPurgeSkipsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := pokecache.NewDiskStore(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	disk.Add("https://example.com", []byte("testdata"), pokecache.Validators{})
+	for _, name := range []string{".tmp-123", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not an entry"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := disk.Purge(""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("expected only the foreign files to be left, got %v", files)
+	}
+}
+
+func TestStatsAndPurge(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute, pokecache.WithMaxEntries(2))
+	defer cache.Close()
+	cache.Add("https://example.com/pokemon/a", []byte("aa"))
+	cache.Add("https://example.com/pokemon/b", []byte("bbb"))
+	cache.Add("https://example.com/type/c", []byte("c"))
+	cache.Get("https://example.com/type/c")
+	cache.Get("https://example.com/pokemon/a")
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Entries != 2 || stats.Bytes != 4 {
+		t.Errorf("expected 2 entries and 4 bytes, got %+v", stats)
+	}
+	if entries := cache.Entries(); entries[0].Key != "https://example.com/type/c" {
+		t.Errorf("expected the most recently used entry first, got %+v", entries)
+	}
+
+	removed, err := cache.Purge("https://example.com/pokemon/")
+	if err != nil || removed != 1 {
+		t.Errorf("expected 1 entry purged, got %d, %v", removed, err)
+	}
+	if _, ok := cache.Get("https://example.com/type/c"); !ok {
+		t.Errorf("expected entries outside the prefix to be kept")
+	}
+}
+
+func TestGetOrFetchDeduplicates(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func() ([]byte, error) {
+		loads.Add(1)
+		<-release
+		return []byte("testdata"), nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := cache.GetOrFetch("https://example.com", load)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results <- string(val)
+		}()
+	}
+	// give every caller time to join the load before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("expected 1 load, got %d", n)
+	}
+	for val := range results {
+		if val != "testdata" {
+			t.Errorf("expected every caller to get the value, got %q", val)
+		}
+	}
+	if _, ok := cache.Get("https://example.com"); !ok {
+		t.Errorf("expected the loaded value to be cached")
+	}
+}
+
+func TestGetOrFetchSurvivesPanickingLoad(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected the load's panic to reach its caller")
+			}
+		}()
+		cache.GetOrFetch("https://example.com", func() ([]byte, error) {
+			panic("bad decode")
+		})
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		val, err := cache.GetOrFetch("https://example.com", func() ([]byte, error) {
+			return []byte("testdata"), nil
+		})
+		if err != nil || string(val) != "testdata" {
+			t.Errorf("expected a fresh load, got %q, %v", val, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GetOrFetch is still waiting on the panicked load")
+	}
+}
+
+func TestGetOrFetchDoesNotCacheErrors(t *testing.T) {
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	_, err := cache.GetOrFetch("https://example.com", func() ([]byte, error) {
+		return nil, errors.New("network down")
+	})
+	if err == nil {
+		t.Errorf("expected the load error")
+	}
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected a failed load not to be cached")
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/typechart"
+)
+
+// Command callbacks return one of these instead of printing, so the same
+// result can be rendered as text, JSON, YAML or a table.
+
+type messageResult struct {
+	Message string `json:"message"`
+}
+
+func (r messageResult) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintln(w, r.Message)
+	return err
+}
+
+type helpEntry struct {
+	Name        string            `json:"name"`
+	Usage       string            `json:"usage"`
+	Description string            `json:"description"`
+	Flags       map[string]string `json:"flags,omitempty"`
+}
+
+type helpResult struct {
+	Commands []helpEntry `json:"commands"`
+}
+
+func (r helpResult) WriteText(w io.Writer) error {
+	cmdDescriptions := ""
+	for _, cmd := range r.Commands {
+		cmdDescriptions = cmdDescriptions + "\n" + cmd.Usage + ": " + cmd.Description
+	}
+	_, err := fmt.Fprintln(w, "Welcome to the Pokedex!", "Usage:", "", cmdDescriptions)
+	return err
+}
+
+func (r helpResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Commands))
+	for _, cmd := range r.Commands {
+		rows = append(rows, []string{cmd.Usage, cmd.Description})
+	}
+	return []string{"usage", "description"}, rows
+}
+
+// WriteText prints the usage of a single command, for help <command> and -h.
+func (r helpEntry) WriteText(w io.Writer) error {
+	fmt.Fprintln(w, "usage:", r.Usage)
+	fmt.Fprintln(w, r.Description)
+	for _, name := range sortedKeys(r.Flags) {
+		fmt.Fprintf(w, "  --%s\t%s\n", name, r.Flags[name])
+	}
+	return nil
+}
+
+type locationAreaPage struct {
+	Areas    []string `json:"areas"`
+	Previous string   `json:"previous"`
+	Next     string   `json:"next"`
+}
+
+func (r locationAreaPage) WriteText(w io.Writer) error {
+	for _, area := range r.Areas {
+		fmt.Fprintln(w, area)
+	}
+	fmt.Fprintln(w, r.Previous)
+	_, err := fmt.Fprintln(w, r.Next)
+	return err
+}
+
+func (r locationAreaPage) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Areas))
+	for _, area := range r.Areas {
+		rows = append(rows, []string{area})
+	}
+	return []string{"area"}, rows
+}
+
+type exploreResult struct {
+	Area       string             `json:"area"`
+	Version    string             `json:"version,omitempty"`
+	Pokemon    []string           `json:"pokemon"`
+	Encounters []exploreEncounter `json:"encounters"`
+}
+
+type exploreEncounter struct {
+	Pokemon  string             `json:"pokemon"`
+	Versions []versionEncounter `json:"versions"`
+}
+
+func (r exploreResult) WriteText(w io.Writer) error {
+	for _, encounter := range r.Encounters {
+		versions := make([]string, 0, len(encounter.Versions))
+		for _, v := range encounter.Versions {
+			versions = append(versions, v.String())
+		}
+		if len(versions) == 0 {
+			fmt.Fprintln(w, encounter.Pokemon)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", encounter.Pokemon, strings.Join(versions, "; "))
+	}
+	return nil
+}
+
+func (r exploreResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Encounters))
+	for _, encounter := range r.Encounters {
+		for _, v := range encounter.Versions {
+			rows = append(rows, []string{encounter.Pokemon, v.Version, fmt.Sprint(v.Chance, "%"), v.levels(), strings.Join(v.Methods, ", ")})
+		}
+		if len(encounter.Versions) == 0 {
+			rows = append(rows, []string{encounter.Pokemon, "", "", "", ""})
+		}
+	}
+	return []string{"pokemon", "version", "chance", "levels", "methods"}, rows
+}
+
+type catchResult struct {
+	Pokemon   string `json:"pokemon"`
+	Level     int    `json:"level"`
+	Ball      string `json:"ball"`
+	CatchRate int    `json:"catch_rate"`
+	Roll      int    `json:"roll"`
+	Caught    bool   `json:"caught"`
+}
+
+func (r catchResult) WriteText(w io.Writer) error {
+	fmt.Fprint(w, "A wild ", r.Pokemon, " (level ", r.Level, ") appeared!\n")
+	fmt.Fprint(w, "Throwing a ", r.Ball, " at ", r.Pokemon, "...\n")
+	if r.Caught {
+		fmt.Fprintln(w, r.Pokemon, "was caught!")
+	} else {
+		fmt.Fprintln(w, r.Pokemon, "escaped!")
+	}
+	return nil
+}
+
+type statValue struct {
+	Name     string `json:"name"`
+	BaseStat int    `json:"base_stat"`
+}
+
+type pokemonDetails struct {
+	Name     string      `json:"name"`
+	Level    int         `json:"level"`
+	Height   int         `json:"height"`
+	Weight   int         `json:"weight"`
+	Stats    []statValue `json:"stats"`
+	Types    []string    `json:"types"`
+	CaughtAt time.Time   `json:"caught_at"`
+	Area     string      `json:"area"`
+	// Weaknesses is filled in by inspect from the type chart.
+	Weaknesses []typechart.Multiplier `json:"weaknesses,omitempty"`
+	// Sprite is the terminal art inspect drew, shown above the text.
+	Sprite string `json:"-"`
+}
+
+func newPokemonDetails(caught caughtPokemon) pokemonDetails {
+	value := caught.Pokemon
+	details := pokemonDetails{
+		Name:     value.Name,
+		Level:    caught.Level,
+		Height:   value.Height,
+		Weight:   value.Weight,
+		Stats:    []statValue{},
+		Types:    []string{},
+		CaughtAt: caught.CaughtAt,
+		Area:     caught.Area,
+	}
+	for _, val := range value.Stats {
+		details.Stats = append(details.Stats, statValue{Name: val.Stat.Name, BaseStat: val.BaseStat})
+	}
+	for _, val := range value.Types {
+		details.Types = append(details.Types, val.Type.Name)
+	}
+	return details
+}
+
+func (r pokemonDetails) WriteText(w io.Writer) error {
+	fmt.Fprint(w, r.Sprite)
+	fmt.Fprintln(w, "Name:", r.Name)
+	fmt.Fprintln(w, "Level:", r.Level)
+	fmt.Fprintln(w, "Height:", r.Height)
+	fmt.Fprintln(w, "Weight:", r.Weight)
+	fmt.Fprintln(w, "Stats:")
+	for _, val := range r.Stats {
+		fmt.Fprint(w, "	-", val.Name, ":", val.BaseStat, "\n")
+	}
+	fmt.Fprintln(w, "Types:")
+	for _, val := range r.Types {
+		fmt.Fprint(w, "	-", val, "\n")
+	}
+	if len(r.Weaknesses) > 0 {
+		fmt.Fprintln(w, "Weaknesses:")
+		for _, val := range r.Weaknesses {
+			fmt.Fprint(w, "	-", val.Type, ": ", formatMultiplier(val.Multiplier), "\n")
+		}
+	}
+	return nil
+}
+
+func (r pokemonDetails) Table() ([]string, [][]string) {
+	rows := [][]string{
+		{"name", r.Name},
+		{"level", strconv.Itoa(r.Level)},
+		{"height", strconv.Itoa(r.Height)},
+		{"weight", strconv.Itoa(r.Weight)},
+	}
+	for _, val := range r.Stats {
+		rows = append(rows, []string{val.Name, strconv.Itoa(val.BaseStat)})
+	}
+	for i, val := range r.Types {
+		rows = append(rows, []string{"type " + strconv.Itoa(i+1), val})
+	}
+	return []string{"field", "value"}, rows
+}
+
+type pokedexEntry struct {
+	Name     string    `json:"name"`
+	Level    int       `json:"level"`
+	Area     string    `json:"area"`
+	CaughtAt time.Time `json:"caught_at"`
+}
+
+type pokedexResult struct {
+	Pokemon []pokedexEntry `json:"pokemon"`
+}
+
+func (r pokedexResult) WriteText(w io.Writer) error {
+	fmt.Fprintln(w, "Your pokedex:")
+	for _, entry := range r.Pokemon {
+		fmt.Fprintln(w, "-", entry.Name)
+	}
+	return nil
+}
+
+func (r pokedexResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Pokemon))
+	for _, entry := range r.Pokemon {
+		rows = append(rows, []string{entry.Name, strconv.Itoa(entry.Level), entry.Area, entry.CaughtAt.Format(time.DateTime)})
+	}
+	return []string{"name", "level", "area", "caught at"}, rows
+}
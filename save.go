@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+// saveFileVersion is bumped whenever the save file layout changes. Add a
+// migration below for every bump so older saves keep loading.
+const saveFileVersion = 1
+
+// saveMigrations[i] upgrades a decoded save file from version i+1 to i+2.
+var saveMigrations = []func(map[string]any) error{}
+
+type caughtPokemon struct {
+	Pokemon  pokeapi.PokemonAPIResponse `json:"pokemon"`
+	Level    int                        `json:"level"`
+	CaughtAt time.Time                  `json:"caught_at"`
+	Area     string                     `json:"area"`
+}
+
+type saveFile struct {
+	Version int                      `json:"version"`
+	SavedAt time.Time                `json:"saved_at"`
+	Pokedex map[string]caughtPokemon `json:"pokedex"`
+}
+
+// dataDir is where pokedexcli keeps its files, following XDG_DATA_HOME. It
+// is empty when no home directory can be found.
+func dataDir() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "pokedexcli")
+}
+
+func defaultSavePath() string {
+	return filepath.Join(dataDir(), "pokedex.json")
+}
+
+func savePokedex(path string, pokedex map[string]caughtPokemon) error {
+	data, err := json.Marshal(saveFile{
+		Version: saveFileVersion,
+		SavedAt: time.Now(),
+		Pokedex: pokedex,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadPokedex(path string) (map[string]caughtPokemon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s is not a valid save file: %w", path, err)
+	}
+	version, _ := raw["version"].(float64)
+	if version < 1 || version > saveFileVersion || version != float64(int(version)) {
+		return nil, fmt.Errorf("%s has unsupported save version %v", path, raw["version"])
+	}
+	for v := int(version); v < saveFileVersion; v++ {
+		if err := saveMigrations[v-1](raw); err != nil {
+			return nil, fmt.Errorf("migrating %s to version %d: %w", path, v+1, err)
+		}
+	}
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var save saveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+	if save.Pokedex == nil {
+		save.Pokedex = map[string]caughtPokemon{}
+	}
+	return save.Pokedex, nil
+}
+
+// openPokedex loads the save file at the start of a session. The pokedex is
+// only saved automatically if this succeeds or there is no file yet, so a
+// save that fails to load is never overwritten behind the user's back.
+func openPokedex(config *config) error {
+	config.pokedex = map[string]caughtPokemon{}
+	pokedex, err := loadPokedex(config.savePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		config.pokedexLoaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	config.pokedex = pokedex
+	config.pokedexLoaded = true
+	return nil
+}
+
+// autosave saves the pokedex to the save file, unless it failed to load.
+func autosave(config *config) {
+	if !config.pokedexLoaded {
+		return
+	}
+	if err := savePokedex(config.savePath, config.pokedex); err != nil {
+		fmt.Fprintln(os.Stderr, "could not save the pokedex:", err)
+	}
+}
+
+func commandSave(config *config, args commandArgs) (any, error) {
+	path := args.arg(0)
+	if path == "" {
+		path = config.savePath
+	}
+	if err := savePokedex(path, config.pokedex); err != nil {
+		return nil, err
+	}
+	if path == config.savePath {
+		// the user chose to replace the file, autosaving is safe again
+		config.pokedexLoaded = true
+	}
+	return messageResult{Message: "Pokedex saved to " + path}, nil
+}
+
+func commandLoad(config *config, args commandArgs) (any, error) {
+	path := args.arg(0)
+	if path == "" {
+		path = config.savePath
+	}
+	pokedex, err := loadPokedex(path)
+	if err != nil {
+		return nil, err
+	}
+	config.pokedex = pokedex
+	message := fmt.Sprint("Loaded ", len(pokedex), " pokemon from ", path)
+	if path == config.savePath {
+		config.pokedexLoaded = true
+	} else {
+		// autosaving now would replace the save file with another pokedex
+		config.pokedexLoaded = false
+		message += ", autosave is off until you run save"
+	}
+	return messageResult{Message: message}, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+)
+
+func TestSaveLoadPokedex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "pokedex.json")
+	caughtAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pokedex := map[string]caughtPokemon{
+		"pikachu": {
+			Pokemon:  pokeapi.PokemonAPIResponse{Name: "pikachu", BaseExperience: 112},
+			CaughtAt: caughtAt,
+			Area:     "viridian-forest-area",
+		},
+	}
+	if err := savePokedex(path, pokedex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := loadPokedex(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := loaded["pikachu"]
+	if got.Pokemon.Name != "pikachu" || got.Area != "viridian-forest-area" || !got.CaughtAt.Equal(caughtAt) {
+		t.Errorf("unexpected entry %+v", got)
+	}
+}
+
+func TestUnloadableSaveIsNotOverwritten(t *testing.T) {
+	for name, content := range map[string]string{
+		"corrupt": `{"version":1,"pokedex":`,
+		"future":  `{"version":99,"pokedex":{}}`,
+	} {
+		path := filepath.Join(t.TempDir(), "pokedex.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config := &config{savePath: path}
+		if err := openPokedex(config); err == nil {
+			t.Errorf("%s: expected the save file not to load", name)
+		}
+		config.pokedex["pikachu"] = caughtPokemon{Pokemon: pokeapi.PokemonAPIResponse{Name: "pikachu"}}
+		shutdown(config)
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != content {
+			t.Errorf("%s: save file was overwritten with %q, %v", name, data, err)
+		}
+	}
+}
+
+func TestMissingSaveIsCreated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex.json")
+	config := &config{savePath: path}
+	if err := openPokedex(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config.pokedex["pikachu"] = caughtPokemon{Pokemon: pokeapi.PokemonAPIResponse{Name: "pikachu"}}
+	shutdown(config)
+	if pokedex, err := loadPokedex(path); err != nil || len(pokedex) != 1 {
+		t.Errorf("expected the pokedex to be saved, got %v, %v", pokedex, err)
+	}
+}
+
+func TestLoadOtherFileKeepsSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pokedex.json")
+	other := filepath.Join(dir, "other.json")
+	if err := savePokedex(path, map[string]caughtPokemon{"pikachu": {}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := savePokedex(other, map[string]caughtPokemon{"eevee": {}}); err != nil {
+		t.Fatal(err)
+	}
+	config := &config{savePath: path}
+	if err := openPokedex(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := commandLoad(config, commandArgs{positional: []string{other}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	autosave(config)
+	pokedex, err := loadPokedex(path)
+	if _, ok := pokedex["pikachu"]; err != nil || len(pokedex) != 1 || !ok {
+		t.Errorf("save file was replaced with %v, %v", pokedex, err)
+	}
+	// loading the save file itself turns autosave back on
+	if _, err := commandLoad(config, commandArgs{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.pokedexLoaded {
+		t.Error("expected autosave to be on after loading the save file")
+	}
+}
+
+func TestLoadRejectsNewerSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex.json")
+	content := fmt.Sprintf(`{"version":%d,"pokedex":{"pikachu":{"pokemon":{"name":"pikachu"}}}}`, saveFileVersion+1)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadPokedex(path); err == nil || !strings.Contains(err.Error(), "unsupported save version") {
+		t.Errorf("expected an unsupported version error, got %v", err)
+	}
+	// catching or evolving autosaves, which must leave the file alone
+	config := &config{savePath: path}
+	openPokedex(config)
+	config.pokedex["eevee"] = caughtPokemon{Pokemon: pokeapi.PokemonAPIResponse{Name: "eevee"}}
+	autosave(config)
+	if data, err := os.ReadFile(path); err != nil || string(data) != content {
+		t.Errorf("save file was overwritten with %q, %v", data, err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// onShutdown registers cleanup to run when the program exits, after the
+// pokedex is saved. Cleanups run in reverse order of registration.
+func (c *config) onShutdown(cleanup func() error) {
+	c.cleanups = append(c.cleanups, cleanup)
+}
+
+// shutdown saves the pokedex, unless it failed to load, and runs the
+// registered cleanups. Only the first call does anything, so exit and a
+// signal arriving together are safe.
+func shutdown(config *config) {
+	config.shutdownOnce.Do(func() {
+		autosave(config)
+		for i := len(config.cleanups) - 1; i >= 0; i-- {
+			if err := config.cleanups[i](); err != nil {
+				fmt.Fprintln(os.Stderr, "shutdown:", err)
+			}
+		}
+	})
+}
+
+// shutdownGrace is how long a signal waits for the running command to stop
+// before the program exits without saving.
+const shutdownGrace = 5 * time.Second
+
+// context returns the context long commands such as sync run under, which a
+// signal cancels.
+func (c *config) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// whileBusy runs fn, a top-level command, while holding c.busy so that a
+// signal doesn't save the pokedex while fn is changing it.
+func (c *config) whileBusy(fn func() error) error {
+	c.busy.Lock()
+	err := fn()
+	c.busy.Unlock()
+	if c.context().Err() != nil {
+		// a signal is shutting down: leave saving and exiting to it rather
+		// than reading or running more commands
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		select {}
+	}
+	return err
+}
+
+// handleSignals shuts down and exits on SIGINT or SIGTERM, with the usual
+// 128+signal status. It first cancels c.ctx and waits for the running
+// command, if any, to return, so shutdown never races with it. A command that
+// doesn't stop within shutdownGrace, or a second signal, makes the program
+// exit without saving; catches and evolutions were saved as they happened.
+func handleSignals(config *config) {
+	config.ctx, config.cancel = context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		if config.interactive {
+			fmt.Println()
+		}
+		config.cancel()
+		idle := make(chan struct{})
+		go func() {
+			// never unlocked, so no command starts after this
+			config.busy.Lock()
+			close(idle)
+		}()
+		select {
+		case <-idle:
+			shutdown(config)
+		case sig = <-signals:
+			fmt.Fprintln(os.Stderr, "exiting without saving")
+		case <-time.After(shutdownGrace):
+			fmt.Fprintln(os.Stderr, "the running command did not stop, exiting without saving")
+		}
+		status := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			status = 128 + int(s)
+		}
+		os.Exit(status)
+	}()
+}
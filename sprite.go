@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/fuzzy"
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+	"github.com/tholho/pokedexcli/internal/render"
+	"github.com/tholho/pokedexcli/internal/termart"
+)
+
+// spriteWidth is the most columns a sprite is drawn on.
+const spriteWidth = 48
+
+// spriteURL picks the front sprite of a pokemon. version is "default",
+// "shiny", a game such as red-blue, or a generation such as generation-i, in
+// which case its first game with a sprite is used.
+func spriteURL(pokemon pokeapi.PokemonAPIResponse, version string) (string, error) {
+	switch version {
+	case "", "default":
+		return pokemon.Sprites.FrontDefault, nil
+	case "shiny":
+		return pokemon.Sprites.FrontShiny, nil
+	}
+	// the versions are nested two levels deep under dozens of typed fields,
+	// so walk them generically
+	data, err := json.Marshal(pokemon.Sprites.Versions)
+	if err != nil {
+		return "", err
+	}
+	var generations map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &generations); err != nil {
+		return "", err
+	}
+	known := []string{"default", "shiny"}
+	for _, generation := range sortedKeys(generations) {
+		games := sortedKeys(generations[generation])
+		known = append(known, generation)
+		known = append(known, games...)
+		for _, game := range games {
+			if generation != version && game != version {
+				continue
+			}
+			var sprites struct {
+				FrontDefault string `json:"front_default"`
+			}
+			json.Unmarshal(generations[generation][game], &sprites)
+			if sprites.FrontDefault != "" {
+				return sprites.FrontDefault, nil
+			}
+		}
+	}
+	for _, name := range known {
+		if name == version {
+			return "", nil
+		}
+	}
+	suggestions := fuzzy.Rank(version, known)
+	return "", fmt.Errorf("unknown sprite version %q%s", version, didYouMean(suggestions[:min(len(suggestions), 3)]))
+}
+
+// drawSprite downloads the sprite at url, through the cache, and draws it.
+func drawSprite(config *config, url string, mode termart.Mode) (string, error) {
+	data, err := config.client.GetSprite(url)
+	if err != nil {
+		return "", friendlyError(err, "sprite at "+url)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("decoding %s: %w", url, err)
+	}
+	var art strings.Builder
+	if err := termart.Render(&art, img, mode, spriteWidth); err != nil {
+		return "", err
+	}
+	return art.String(), nil
+}
+
+// spriteArt draws the sprite inspect asked for. Art is only drawn for text
+// output, on a terminal unless --sprite or --art asks for it anyway, and
+// never offline.
+func spriteArt(config *config, args commandArgs, pokemon pokeapi.PokemonAPIResponse) (string, error) {
+	version, wantSprite := args.flag("sprite")
+	artName, wantArt := args.flag("art")
+	format := config.output
+	if name, ok := args.flag("output"); ok {
+		format, _ = render.ParseFormat(name)
+	}
+	terminal := isTerminal(os.Stdout)
+	if format != render.Text || artName == "none" || (!terminal && !wantSprite && !wantArt) {
+		return "", nil
+	}
+	// sync doesn't mirror sprites, which live outside /api/v2
+	if config.offline {
+		if wantSprite || wantArt {
+			return "", errors.New("Sprites are not available offline")
+		}
+		return "", nil
+	}
+	mode := termart.DetectMode(terminal)
+	if wantArt {
+		var err error
+		if mode, err = termart.ParseMode(artName); err != nil {
+			return "", err
+		}
+	}
+	url, err := spriteURL(pokemon, strings.ToLower(version))
+	if err != nil {
+		return "", err
+	}
+	if url == "" {
+		if wantSprite {
+			return "", fmt.Errorf("%s has no %s sprite", pokemon.Name, version)
+		}
+		return "", nil
+	}
+	art, err := drawSprite(config, url, mode)
+	if err != nil && !wantSprite && !wantArt {
+		// a sprite nobody asked for is not worth failing inspect over
+		fmt.Fprintln(os.Stderr, "could not draw the sprite:", err)
+		return "", nil
+	}
+	return art, err
+}
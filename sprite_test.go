@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tholho/pokedexcli/internal/pokeapi"
+	"github.com/tholho/pokedexcli/internal/render"
+)
+
+func TestSpriteURL(t *testing.T) {
+	var pokemon pokeapi.PokemonAPIResponse
+	err := json.Unmarshal([]byte(`{"name":"pikachu","sprites":{
+		"front_default":"default.png","front_shiny":"shiny.png",
+		"versions":{
+			"generation-i":{"red-blue":{"front_default":"red-blue.png"},"yellow":{"front_default":"yellow.png"}},
+			"generation-ii":{"crystal":{"front_default":""},"gold":{"front_default":"gold.png"}}}}}`), &pokemon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"":              "default.png",
+		"shiny":         "shiny.png",
+		"yellow":        "yellow.png",
+		"generation-i":  "red-blue.png",
+		"generation-ii": "gold.png",
+		"crystal":       "",
+	}
+	for version, want := range cases {
+		got, err := spriteURL(pokemon, version)
+		if err != nil || got != want {
+			t.Errorf("spriteURL(%q) = %q, %v, want %q", version, got, err, want)
+		}
+	}
+	if _, err := spriteURL(pokemon, "yelow"); err == nil || err.Error() != `unknown sprite version "yelow", did you mean yellow?` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSpriteArtOffline(t *testing.T) {
+	config := &config{offline: true, output: render.Text}
+	pokemon := pokeapi.PokemonAPIResponse{Name: "pikachu"}
+	pokemon.Sprites.FrontDefault = "https://example.com/pikachu.png"
+	// without a terminal only an explicit request reaches the offline check
+	if _, err := spriteArt(config, parseArgs([]string{"--sprite=shiny"}), pokemon); err == nil || err.Error() != "Sprites are not available offline" {
+		t.Errorf("expected asking for a sprite offline to fail, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tholho/pokedexcli/internal/mirror"
+)
+
+type syncResult struct {
+	Dir             string  `json:"dir"`
+	Fetched         int     `json:"fetched"`
+	Skipped         int     `json:"skipped"`
+	Missing         int     `json:"missing"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+func (r syncResult) WriteText(w io.Writer) error {
+	duration := time.Duration(r.DurationSeconds * float64(time.Second)).Round(time.Second)
+	fmt.Fprintf(w, "Mirrored PokeAPI to %s in %s\n", r.Dir, duration)
+	fmt.Fprintf(w, "Fetched %d resources, kept %d from an earlier sync", r.Fetched, r.Skipped)
+	if r.Missing > 0 {
+		fmt.Fprintf(w, ", %d referenced but missing from the API", r.Missing)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use it with -offline")
+	return nil
+}
+
+// syncProgress prints progress to stderr: on a terminal as a line redrawn in
+// place, otherwise once per finished phase.
+func syncProgress(p mirror.Progress) {
+	if isTerminal(os.Stderr) {
+		fmt.Fprintf(os.Stderr, "\r%s: %d/%d", p.Phase, p.Done, p.Total)
+		if p.Done == p.Total {
+			fmt.Fprintln(os.Stderr)
+		}
+	} else if p.Done == p.Total {
+		fmt.Fprintf(os.Stderr, "%s: %d/%d\n", p.Phase, p.Done, p.Total)
+	}
+}
+
+func commandSync(config *config, args commandArgs) (any, error) {
+	if config.offline {
+		return nil, errors.New("sync downloads from PokeAPI, run it without -offline")
+	}
+	dir, ok := args.flag("dir")
+	if !ok {
+		dir = config.offlineDir
+	}
+	workers := 4
+	if value, ok := args.flag("workers"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("--workers must be a positive number, got %q", value)
+		}
+		workers = n
+	}
+	// a signal stops the sync early, still saving the manifest to resume from
+	res, err := mirror.Sync(config.context(), config.client, dir, mirror.Options{
+		Workers:  workers,
+		Progress: syncProgress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sync stopped after fetching %d resources, run it again to resume: %w", res.Fetched, friendlyError(err, "such resource"))
+	}
+	return syncResult{
+		Dir:             dir,
+		Fetched:         res.Fetched,
+		Skipped:         res.Skipped,
+		Missing:         res.Missing,
+		DurationSeconds: res.Duration.Seconds(),
+	}, nil
+}
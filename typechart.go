@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tholho/pokedexcli/internal/typechart"
+)
+
+// resolveTypes returns the types of a pokemon, looking in the pokedex before
+// asking the API. A type name stands for itself.
+func resolveTypes(config *config, name string) ([]string, error) {
+	if typechart.IsType(name) {
+		return []string{name}, nil
+	}
+	pokemon := config.pokedex[name].Pokemon
+	if pokemon.Name == "" {
+		var err error
+		if pokemon, err = config.client.GetPokemon(name); err != nil {
+			return nil, friendlyError(err, "pokemon or type called "+name)
+		}
+	}
+	types := make([]string, 0, len(pokemon.Types))
+	for _, t := range pokemon.Types {
+		types = append(types, t.Type.Name)
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("could not find the types of %s", name)
+	}
+	return types, nil
+}
+
+func formatMultiplier(m float64) string {
+	return "x" + strconv.FormatFloat(m, 'g', -1, 64)
+}
+
+type matchupResult struct {
+	Attacker      string                 `json:"attacker"`
+	AttackerTypes []string               `json:"attacker_types"`
+	Defender      string                 `json:"defender"`
+	DefenderTypes []string               `json:"defender_types"`
+	Matchups      []typechart.Multiplier `json:"matchups"`
+}
+
+func (r matchupResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s (%s) against %s (%s):\n", r.Attacker, strings.Join(r.AttackerTypes, "/"), r.Defender, strings.Join(r.DefenderTypes, "/"))
+	for _, m := range r.Matchups {
+		fmt.Fprint(w, "	-", m.Type, ": ", formatMultiplier(m.Multiplier), "\n")
+	}
+	return nil
+}
+
+func (r matchupResult) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(r.Matchups))
+	for _, m := range r.Matchups {
+		rows = append(rows, []string{m.Type, formatMultiplier(m.Multiplier)})
+	}
+	return []string{"attacking type", "multiplier"}, rows
+}
+
+func commandMatchup(config *config, args commandArgs) (any, error) {
+	attacker, defender := strings.ToLower(args.arg(0)), strings.ToLower(args.arg(1))
+	attackerTypes, err := resolveTypes(config, attacker)
+	if err != nil {
+		return nil, err
+	}
+	defenderTypes, err := resolveTypes(config, defender)
+	if err != nil {
+		return nil, err
+	}
+	res := matchupResult{
+		Attacker:      attacker,
+		AttackerTypes: attackerTypes,
+		Defender:      defender,
+		DefenderTypes: defenderTypes,
+	}
+	for _, t := range attackerTypes {
+		m, err := config.typeChart.Effectiveness(t, defenderTypes)
+		if err != nil {
+			return nil, err
+		}
+		res.Matchups = append(res.Matchups, typechart.Multiplier{Type: t, Multiplier: m})
+	}
+	return res, nil
+}
+
+type weaknessResult struct {
+	Pokemon     string                 `json:"pokemon"`
+	Types       []string               `json:"types"`
+	Weaknesses  []typechart.Multiplier `json:"weaknesses"`
+	Resistances []typechart.Multiplier `json:"resistances"`
+	Immunities  []string               `json:"immunities"`
+}
+
+func (r weaknessResult) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s (%s)\n", r.Pokemon, strings.Join(r.Types, "/"))
+	fmt.Fprintln(w, "Weak to:")
+	for _, m := range r.Weaknesses {
+		fmt.Fprint(w, "	-", m.Type, ": ", formatMultiplier(m.Multiplier), "\n")
+	}
+	fmt.Fprintln(w, "Resists:")
+	for _, m := range r.Resistances {
+		fmt.Fprint(w, "	-", m.Type, ": ", formatMultiplier(m.Multiplier), "\n")
+	}
+	fmt.Fprintln(w, "Immune to:")
+	for _, t := range r.Immunities {
+		fmt.Fprint(w, "	-", t, "\n")
+	}
+	return nil
+}
+
+func (r weaknessResult) Table() ([]string, [][]string) {
+	var rows [][]string
+	for _, m := range r.Weaknesses {
+		rows = append(rows, []string{m.Type, formatMultiplier(m.Multiplier)})
+	}
+	for _, m := range r.Resistances {
+		rows = append(rows, []string{m.Type, formatMultiplier(m.Multiplier)})
+	}
+	for _, t := range r.Immunities {
+		rows = append(rows, []string{t, formatMultiplier(0)})
+	}
+	return []string{"attacking type", "multiplier"}, rows
+}
+
+func commandWeakness(config *config, args commandArgs) (any, error) {
+	pokemon := strings.ToLower(args.arg(0))
+	types, err := resolveTypes(config, pokemon)
+	if err != nil {
+		return nil, err
+	}
+	multipliers, err := config.typeChart.Weaknesses(types)
+	if err != nil {
+		return nil, err
+	}
+	res := weaknessResult{
+		Pokemon:     pokemon,
+		Types:       types,
+		Weaknesses:  []typechart.Multiplier{},
+		Resistances: []typechart.Multiplier{},
+		Immunities:  []string{},
+	}
+	for _, m := range multipliers {
+		switch {
+		case m.Multiplier == 0:
+			res.Immunities = append(res.Immunities, m.Type)
+		case m.Multiplier > 1:
+			res.Weaknesses = append(res.Weaknesses, m)
+		case m.Multiplier < 1:
+			res.Resistances = append(res.Resistances, m)
+		}
+	}
+	return res, nil
+}
+
+// weaknessesOf lists the attacking types that deal extra damage, for
+// inspect.
+func weaknessesOf(config *config, types []string) ([]typechart.Multiplier, error) {
+	multipliers, err := config.typeChart.Weaknesses(types)
+	if err != nil {
+		return nil, err
+	}
+	var weaknesses []typechart.Multiplier
+	for _, m := range multipliers {
+		if m.Multiplier > 1 {
+			weaknesses = append(weaknesses, m)
+		}
+	}
+	return weaknesses, nil
+}